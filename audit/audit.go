@@ -0,0 +1,84 @@
+package audit
+
+import (
+	"encoding/json"
+
+	"belajar-golang-fiber/entity"
+)
+
+const (
+	ActionRegister       = "register"
+	ActionCreate         = "create"
+	ActionUpdate         = "update"
+	ActionPasswordChange = "password_change"
+	ActionDelete         = "delete"
+	ActionRestore        = "restore"
+)
+
+// Masked menggantikan nilai field rahasia (password) di audit log
+const Masked = "******"
+
+type Change struct {
+	Field  string  `json:"field"`
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// NewLog membuat satu baris user_logs. Simpan di dalam transaksi yang sama dengan perubahan user
+// supaya log hanya tersimpan jika perubahan user juga berhasil disimpan
+func NewLog(userId string, action string, changes []Change) (*entity.UserLogs, error) {
+	log := &entity.UserLogs{UserId: userId, Action: action}
+	if len(changes) > 0 {
+		content, err := json.Marshal(changes)
+		if err != nil {
+			return nil, err
+		}
+		log.Changes = string(content)
+	}
+	return log, nil
+}
+
+// Diff membandingkan dua kondisi user, before nil berarti user baru dibuat dan after nil berarti user dihapus.
+// Perubahan password tidak termasuk di sini, gunakan PasswordChanged
+func Diff(before *entity.User, after *entity.User) []Change {
+	var changes []Change
+	add := func(field string, get func(*entity.User) string) {
+		change := Change{Field: field}
+		if before != nil {
+			value := get(before)
+			change.Before = &value
+		}
+		if after != nil {
+			value := get(after)
+			change.After = &value
+		}
+		if change.Before != nil && change.After != nil && *change.Before == *change.After {
+			return
+		}
+		changes = append(changes, change)
+	}
+
+	add("id", func(user *entity.User) string { return user.ID })
+	add("first_name", func(user *entity.User) string { return user.Name.FirstName })
+	add("middle_name", func(user *entity.User) string { return user.Name.MiddleName })
+	add("last_name", func(user *entity.User) string { return user.Name.LastName })
+	if before == nil || after == nil {
+		add("password", func(user *entity.User) string { return Masked })
+	}
+	return changes
+}
+
+// PasswordChanged adalah isi log untuk perubahan password, nilainya selalu disamarkan
+func PasswordChanged() []Change {
+	masked := Masked
+	return []Change{{Field: "password", Before: &masked, After: &masked}}
+}
+
+// Changes membaca kembali kolom changes dari user_logs
+func Changes(log *entity.UserLogs) []Change {
+	var changes []Change
+	if log.Changes != "" {
+		json.Unmarshal([]byte(log.Changes), &changes)
+	}
+	return changes
+}
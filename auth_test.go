@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+func newAuthApp(db *gorm.DB) *fiber.App {
+	authApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	store := session.New(session.Config{Expiration: time.Minute})
+	authService := service.NewAuthService(repository.NewGormStore(db), security.NewPasswordHasher(bcrypt.MinCost))
+	controller.NewAuthController(authService, store, validation.New()).Route(authApp)
+	return authApp
+}
+
+func login(t *testing.T, authApp *fiber.App, username string, password string) (int, string) {
+	body := strings.NewReader(`{"username":"` + username + `","password":"` + password + `"}`)
+	request := httptest.NewRequest("POST", "/login", body)
+	request.Header.Set("Content-Type", "application/json")
+	response, err := authApp.Test(request)
+	assert.Nil(t, err)
+
+	for _, cookie := range response.Cookies() {
+		if cookie.Name == "session_id" {
+			return response.StatusCode, cookie.Value
+		}
+	}
+	return response.StatusCode, ""
+}
+
+func TestLoginLogout(t *testing.T) {
+	db := testdb.New(t)
+	hashed, err := security.NewPasswordHasher(bcrypt.MinCost).Hash("rahasia")
+	assert.Nil(t, err)
+	assert.Nil(t, db.Create(&entity.User{ID: "auth-1", Password: hashed, Name: entity.Name{FirstName: "Bagus"}}).Error)
+
+	authApp := newAuthApp(db)
+
+	status, _ := login(t, authApp, "auth-1", "salah")
+	assert.Equal(t, 401, status)
+
+	status, _ = login(t, authApp, "tidak-ada", "rahasia")
+	assert.Equal(t, 401, status)
+
+	request := httptest.NewRequest("GET", "/me", nil)
+	response, err := authApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+
+	status, sessionId := login(t, authApp, "auth-1", "rahasia")
+	assert.Equal(t, 200, status)
+	assert.NotEqual(t, "", sessionId)
+
+	request = httptest.NewRequest("GET", "/me", nil)
+	request.Header.Set("Cookie", "session_id="+sessionId)
+	response, err = authApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	userResponse := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, userResponse))
+	assert.Equal(t, "auth-1", userResponse.Data.ID)
+
+	request = httptest.NewRequest("POST", "/logout", nil)
+	request.Header.Set("Cookie", "session_id="+sessionId)
+	response, err = authApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/me", nil)
+	request.Header.Set("Cookie", "session_id="+sessionId)
+	response, err = authApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 401, response.StatusCode)
+}
+
+func TestLoginUpgradePlaintextPassword(t *testing.T) {
+	db := testdb.New(t)
+	assert.Nil(t, db.Create(&entity.User{ID: "auth-2", Password: "rahasia", Name: entity.Name{FirstName: "Bagus"}}).Error)
+
+	status, _ := login(t, newAuthApp(db), "auth-2", "rahasia")
+	assert.Equal(t, 200, status)
+
+	user := entity.User{}
+	assert.Nil(t, db.Take(&user, "id = ?", "auth-2").Error)
+	assert.True(t, security.IsHashed(user.Password))
+	// rehash tidak mengubah ETag, If-Match dari client tetap berlaku
+	assert.Equal(t, int64(1), user.Version)
+}
+
+func register(t *testing.T, authApp *fiber.App, contentType string, body string) (int, string, string) {
+	request := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+	request.Header.Set("Content-Type", contentType)
+	response, err := authApp.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return response.StatusCode, response.Header.Get("Content-Type"), string(bytes)
+}
+
+func TestRegister(t *testing.T) {
+	db := testdb.New(t)
+	authApp := newAuthApp(db)
+
+	status, contentType, body := register(t, authApp, "application/json",
+		`{"username":"reg-json", "password":"rahasia123", "name": "Bagus Eko Wicaksono"}`)
+	assert.Equal(t, 201, status)
+	assert.Contains(t, contentType, "application/json")
+	assert.Contains(t, body, `"username":"reg-json"`)
+
+	status, contentType, body = register(t, authApp, "application/x-www-form-urlencoded",
+		`username=reg-form&password=rahasia123&name=Bagus Wicaksono`)
+	assert.Equal(t, 201, status)
+	assert.Contains(t, contentType, "application/x-www-form-urlencoded")
+	assert.Contains(t, body, "username=reg-form")
+
+	status, contentType, body = register(t, authApp, "application/xml",
+		`<RegisterRequest>
+			<username>reg-xml</username>
+			<password>rahasia123</password>
+			<name>Bagus</name>
+		</RegisterRequest>`)
+	assert.Equal(t, 201, status)
+	assert.Contains(t, contentType, "application/xml")
+	assert.Contains(t, body, "<username>reg-xml</username>")
+
+	user := entity.User{}
+	assert.Nil(t, db.Take(&user, "id = ?", "reg-json").Error)
+	assert.Equal(t, "Bagus", user.Name.FirstName)
+	assert.Equal(t, "Eko", user.Name.MiddleName)
+	assert.Equal(t, "Wicaksono", user.Name.LastName)
+	assert.True(t, security.IsHashed(user.Password))
+
+	user = entity.User{}
+	assert.Nil(t, db.Take(&user, "id = ?", "reg-form").Error)
+	assert.Equal(t, "Bagus", user.Name.FirstName)
+	assert.Equal(t, "", user.Name.MiddleName)
+	assert.Equal(t, "Wicaksono", user.Name.LastName)
+
+	var logs []entity.UserLogs
+	assert.Nil(t, db.Find(&logs, "user_id = ? and action = ?", "reg-json", "register").Error)
+	assert.Equal(t, 1, len(logs))
+
+	var roles []string
+	assert.Nil(t, db.Model(&entity.UserRole{}).Where("user_id = ?", "reg-json").Pluck("role_id", &roles).Error)
+	assert.Equal(t, []string{security.DefaultRole}, roles)
+
+	status, _, _ = register(t, authApp, "application/json",
+		`{"username":"reg-json", "password":"lainnya123", "name": "Orang Lain"}`)
+	assert.Equal(t, 409, status)
+}
+
+func TestRegisterValidation(t *testing.T) {
+	db := testdb.New(t)
+	authApp := newAuthApp(db)
+
+	bodies := map[string]string{
+		"application/json":                  `{"username":"a b", "password":"pendek"}`,
+		"application/x-www-form-urlencoded": `username=a b&password=pendek`,
+		"application/xml":                   `<RegisterRequest><username>a b</username><password>pendek</password></RegisterRequest>`,
+	}
+
+	for contentType, body := range bodies {
+		status, responseType, responseBody := register(t, authApp, contentType, body)
+		assert.Equal(t, 422, status, contentType)
+		assert.Contains(t, responseType, "application/problem+json")
+
+		response := struct {
+			Errors []validation.FieldError `json:"errors"`
+		}{}
+		assert.Nil(t, json.Unmarshal([]byte(responseBody), &response))
+
+		codes := map[string]string{}
+		for _, fieldError := range response.Errors {
+			codes[fieldError.Field] = fieldError.Code
+		}
+		assert.Equal(t, map[string]string{
+			"username": "pattern",
+			"password": "min",
+			"name":     "required",
+		}, codes, contentType)
+	}
+}
+
+// batas bcrypt 72 byte: 40 huruf é hanya 40 karakter tetapi 80 byte
+func TestRegisterMultibytePassword(t *testing.T) {
+	db := testdb.New(t)
+	authApp := newAuthApp(db)
+
+	status, _, body := register(t, authApp, "application/json",
+		`{"username":"reg-utf8", "password":"`+strings.Repeat("é", 40)+`", "name": "Bagus"}`)
+	assert.Equal(t, 422, status)
+	assert.Contains(t, body, `"code":"maxbytes"`)
+
+	status, _, _ = register(t, authApp, "application/json",
+		`{"username":"reg-utf8", "password":"`+strings.Repeat("é", 36)+`", "name": "Bagus"}`)
+	assert.Equal(t, 201, status)
+
+	status, _ = login(t, authApp, "reg-utf8", strings.Repeat("é", 40))
+	assert.Equal(t, 422, status)
+}
+
+// name boleh 255 karakter, tetapi middle_name dan last_name hasil pemecahan nama hanya 100 karakter
+func TestRegisterLongName(t *testing.T) {
+	db := testdb.New(t)
+	authApp := newAuthApp(db)
+
+	name := "Bagus " + strings.TrimSpace(strings.Repeat("Tengah ", 20)) + " Wicaksono"
+	status, _, body := register(t, authApp, "application/json",
+		`{"username":"reg-long", "password":"rahasia123", "name": "`+name+`"}`)
+	assert.Equal(t, 422, status)
+	assert.Contains(t, body, "middle name must be at most 100 characters")
+	assert.NotNil(t, db.Take(&entity.User{}, "id = ?", "reg-long").Error)
+
+	name = "Bagus " + strings.Repeat("w", 101)
+	status, _, body = register(t, authApp, "application/json",
+		`{"username":"reg-long", "password":"rahasia123", "name": "`+name+`"}`)
+	assert.Equal(t, 422, status)
+	assert.Contains(t, body, "last name must be at most 100 characters")
+}
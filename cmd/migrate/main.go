@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/migration"
+)
+
+const usage = `Penggunaan: go run ./cmd/migrate <perintah> [argumen] [flag konfigurasi]
+
+Perintah:
+  up            menjalankan semua migration yang belum dijalankan lalu mengecek schema
+  down [n]      membatalkan n migration terakhir (default 1)
+  status        menampilkan status setiap migration
+  create <nama> membuat file migration baru di ` + migration.Dir + `
+  check         mengecek schema database terhadap model GORM
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+	command, args := os.Args[1], os.Args[2:]
+
+	if command == "create" {
+		if len(args) < 1 {
+			log.Fatal("nama migration wajib diisi")
+		}
+		up, down, err := migration.Create(migration.Dir, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("created", up)
+		fmt.Println("created", down)
+		return
+	}
+
+	steps := 1
+	if command == "down" && len(args) > 0 {
+		if n, err := strconv.Atoi(args[0]); err == nil {
+			steps, args = n, args[1:]
+		}
+	}
+
+	cfg, err := config.Load(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := database.OpenConnection(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	migrator := migration.New(db, migration.Files)
+
+	switch command {
+	case "up":
+		done, err := migrator.Up()
+		for _, m := range done {
+			fmt.Printf("applied  %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := migration.CheckSchema(db, migration.Models...); err != nil {
+			log.Fatal(err)
+		}
+	case "down":
+		done, err := migrator.Down(steps)
+		for _, m := range done {
+			fmt.Printf("reverted %06d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%06d_%-40s %s\n", status.Version, status.Name, state)
+		}
+	case "check":
+		if err := migration.CheckSchema(db, migration.Models...); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("schema OK")
+	default:
+		fmt.Print(usage)
+		os.Exit(2)
+	}
+}
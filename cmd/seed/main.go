@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/fixture"
+	"belajar-golang-fiber/security"
+)
+
+// Penggunaan: go run ./cmd/seed [-reset] [flag konfigurasi] [file...]
+// Tanpa file, semua seed/*.yaml dimuat. Dengan -reset tabel dikosongkan lebih dulu
+func main() {
+	args := os.Args[1:]
+	reset := len(args) > 0 && (args[0] == "-reset" || args[0] == "--reset")
+	if reset {
+		args = args[1:]
+	}
+
+	cfg, files, err := config.LoadWithArgs(args)
+	if err == flag.ErrHelp {
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(files) == 0 {
+		files, err = filepath.Glob("seed/*.yaml")
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	db, err := database.OpenConnection(cfg.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	loader := fixture.New(db)
+	loader.Hasher = security.NewPasswordHasher(cfg.Security.PasswordCost)
+	if reset {
+		err = loader.Reset(files...)
+	} else {
+		err = loader.Load(files...)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, file := range files {
+		fmt.Println("seeded", file)
+	}
+}
@@ -0,0 +1,499 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix adalah prefix environment variable, contoh server.address => APP_SERVER_ADDRESS
+const EnvPrefix = "APP_"
+
+// DefaultFile dibaca jika ada dan tidak ada -config / APP_CONFIG
+const DefaultFile = "config.yaml"
+
+type Config struct {
+	Server   ServerConfig   `yaml:"server"`
+	Database DatabaseConfig `yaml:"database"`
+	Security SecurityConfig `yaml:"security"`
+	Upload   UploadConfig   `yaml:"upload"`
+	Storage  StorageConfig  `yaml:"storage"`
+	Users    UsersConfig    `yaml:"users"`
+	Log      LogConfig      `yaml:"log"`
+	Metrics  MetricsConfig  `yaml:"metrics"`
+	Tracing  TracingConfig  `yaml:"tracing"`
+}
+
+type ServerConfig struct {
+	Address      string        `yaml:"address" usage:"alamat listen HTTP server"`
+	IdleTimeout  time.Duration `yaml:"idle_timeout" usage:"idle timeout koneksi"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" usage:"read timeout request"`
+	WriteTimeout time.Duration `yaml:"write_timeout" usage:"write timeout response"`
+	Prefork      bool          `yaml:"prefork" usage:"aktifkan prefork fiber"`
+	BodyLimit    int           `yaml:"body_limit" usage:"ukuran maksimal body yang dibaca ke memory (byte), body lebih besar di-stream"`
+}
+
+type DatabaseConfig struct {
+	Host            string        `yaml:"host" usage:"host MySQL"`
+	Port            int           `yaml:"port" usage:"port MySQL"`
+	User            string        `yaml:"user" usage:"user MySQL"`
+	Password        string        `yaml:"password" usage:"password MySQL" secret:"true"`
+	Name            string        `yaml:"name" usage:"nama database"`
+	Params          string        `yaml:"params" usage:"parameter tambahan DSN"`
+	MaxOpenConns    int           `yaml:"max_open_conns" usage:"maksimal koneksi terbuka"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" usage:"maksimal koneksi idle"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" usage:"umur maksimal koneksi"`
+	LogLevel        string        `yaml:"log_level" usage:"level log GORM (silent, error, warn, info), info mencatat setiap query"`
+	SlowThreshold   time.Duration `yaml:"slow_threshold" usage:"query yang lebih lama dicatat sebagai slow query (0 = tidak dicek)"`
+	RedactColumns   string        `yaml:"redact_columns" usage:"kolom yang nilai parameternya disamarkan di log query, dipisah koma"`
+}
+
+type SecurityConfig struct {
+	PasswordCost      int           `yaml:"password_cost" usage:"cost bcrypt untuk hash password"`
+	SessionExpiration time.Duration `yaml:"session_expiration" usage:"lama session login"`
+	CookieSecure      bool          `yaml:"cookie_secure" usage:"cookie session hanya dikirim lewat HTTPS"`
+}
+
+// UsersConfig mengatur user yang sudah dihapus (soft delete)
+type UsersConfig struct {
+	DeletedRetention time.Duration `yaml:"deleted_retention" usage:"lama user yang dihapus masih bisa di-restore sebelum dihapus permanen"`
+	PurgeInterval    time.Duration `yaml:"purge_interval" usage:"interval job hapus permanen user (0 = tidak dijalankan)"`
+}
+
+// LogConfig mengatur log aplikasi (log/slog) dan log per request dari middleware
+type LogConfig struct {
+	Level         string  `yaml:"level" usage:"level log (debug, info, warn, error)"`
+	Format        string  `yaml:"format" usage:"format log (json, text)"`
+	SampleRate    float64 `yaml:"sample_rate" usage:"porsi request sukses yang dicatat, 0 sampai 1 (request error selalu dicatat)"`
+	Headers       bool    `yaml:"headers" usage:"sertakan header request di log request"`
+	RedactHeaders string  `yaml:"redact_headers" usage:"header yang nilainya disamarkan di log, dipisah koma"`
+}
+
+// MetricsConfig mengatur endpoint metric Prometheus
+type MetricsConfig struct {
+	Enabled bool   `yaml:"enabled" usage:"aktifkan metric Prometheus"`
+	Path    string `yaml:"path" usage:"path endpoint metric Prometheus"`
+}
+
+// TracingConfig mengatur tracing OpenTelemetry, exporter none mematikan tracing
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" usage:"tujuan span (none, otlp, stdout)"`
+	Endpoint    string  `yaml:"endpoint" usage:"host:port collector OTLP/HTTP (kosong = env OTEL_EXPORTER_OTLP_ENDPOINT atau localhost:4318)"`
+	Insecure    bool    `yaml:"insecure" usage:"kirim ke collector OTLP tanpa TLS"`
+	ServiceName string  `yaml:"service_name" usage:"nama service di trace"`
+	SampleRatio float64 `yaml:"sample_ratio" usage:"porsi trace baru yang di-sample, 0 sampai 1 (trace dari parent mengikuti parent)"`
+}
+
+type UploadConfig struct {
+	TempDir      string `yaml:"temp_dir" usage:"folder file sementara saat upload dicek (kosong = temp dir sistem)"`
+	MaxSize      int    `yaml:"max_size" usage:"ukuran maksimal satu file upload (byte)"`
+	MaxFiles     int    `yaml:"max_files" usage:"jumlah maksimal file dalam satu request upload"`
+	AllowedTypes string `yaml:"allowed_types" usage:"daftar MIME type yang boleh di-upload, dipisah koma"`
+	// Expiration dihitung dari data terakhir yang diterima sesi upload bertahap (tus)
+	Expiration    time.Duration `yaml:"expiration" usage:"lama sesi upload bertahap yang tidak dilanjutkan sebelum dihapus"`
+	PurgeInterval time.Duration `yaml:"purge_interval" usage:"interval job hapus sesi upload kedaluwarsa (0 = tidak dijalankan)"`
+}
+
+// StorageConfig memilih backend penyimpanan file, cukup ganti driver untuk pindah backend
+type StorageConfig struct {
+	Driver       string        `yaml:"driver" usage:"backend penyimpanan file (local, s3)"`
+	Dir          string        `yaml:"dir" usage:"folder penyimpanan driver local"`
+	DownloadURL  string        `yaml:"download_url" usage:"prefix URL download bertanda tangan driver local"`
+	SigningKey   string        `yaml:"signing_key" usage:"kunci HMAC URL download driver local (kosong = acak setiap start)" secret:"true"`
+	SignedURLTTL time.Duration `yaml:"signed_url_ttl" usage:"masa berlaku URL download bertanda tangan"`
+	Endpoint     string        `yaml:"endpoint" usage:"endpoint S3, contoh https://s3.amazonaws.com atau http://127.0.0.1:9000"`
+	Region       string        `yaml:"region" usage:"region S3"`
+	Bucket       string        `yaml:"bucket" usage:"bucket S3"`
+	AccessKey    string        `yaml:"access_key" usage:"access key S3"`
+	SecretKey    string        `yaml:"secret_key" usage:"secret key S3" secret:"true"`
+	PathStyle    bool          `yaml:"path_style" usage:"pakai URL path-style (MinIO dan sejenisnya)"`
+}
+
+// Types mengembalikan AllowedTypes dalam bentuk slice
+func (u UploadConfig) Types() []string {
+	return splitList(u.AllowedTypes)
+}
+
+// splitList memecah daftar yang dipisah koma dan membuang item kosong
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Redacted mengembalikan RedactHeaders dalam bentuk slice
+func (l LogConfig) Redacted() []string {
+	return splitList(l.RedactHeaders)
+}
+
+// Redacted mengembalikan RedactColumns dalam bentuk slice
+func (d DatabaseConfig) Redacted() []string {
+	return splitList(d.RedactColumns)
+}
+
+// DSN membentuk data source name untuk driver MySQL
+func (d DatabaseConfig) DSN() string {
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", d.User, d.Password, d.Host, d.Port, d.Name)
+	if d.Params != "" {
+		dsn += "?" + d.Params
+	}
+	return dsn
+}
+
+// FiberConfig membentuk fiber.Config dari konfigurasi server, ErrorHandler dan Views diisi oleh pemanggil
+func (s ServerConfig) FiberConfig() fiber.Config {
+	return fiber.Config{
+		IdleTimeout:  s.IdleTimeout,
+		ReadTimeout:  s.ReadTimeout,
+		WriteTimeout: s.WriteTimeout,
+		Prefork:      s.Prefork,
+		BodyLimit:    s.BodyLimit,
+		// body yang melebihi BodyLimit tidak ditolak tetapi dibaca bertahap (upload file besar)
+		StreamRequestBody: true,
+	}
+}
+
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Address:      "localhost:3000",
+			IdleTimeout:  5 * time.Second,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 5 * time.Second,
+			BodyLimit:    4 * 1024 * 1024,
+		},
+		Database: DatabaseConfig{
+			Host:            "127.0.0.1",
+			Port:            3306,
+			User:            "root",
+			Name:            "belajar_golang_gorm",
+			Params:          "charset=utf8mb4&parseTime=True&loc=Local",
+			MaxOpenConns:    100,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: time.Hour,
+			LogLevel:        "warn",
+			SlowThreshold:   200 * time.Millisecond,
+			RedactColumns:   "password",
+		},
+		Security: SecurityConfig{
+			PasswordCost:      10,
+			SessionExpiration: 24 * time.Hour,
+		},
+		Upload: UploadConfig{
+			MaxSize:       10 * 1024 * 1024,
+			MaxFiles:      10,
+			AllowedTypes:  "image/jpeg,image/png,image/gif,image/webp,application/pdf,text/plain",
+			Expiration:    24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Storage: StorageConfig{
+			Driver:       "local",
+			Dir:          "./target/uploads",
+			DownloadURL:  "/api/download",
+			SignedURLTTL: 15 * time.Minute,
+			Region:       "us-east-1",
+		},
+		Users: UsersConfig{
+			DeletedRetention: 30 * 24 * time.Hour,
+			PurgeInterval:    time.Hour,
+		},
+		Log: LogConfig{
+			Level:         "info",
+			Format:        "json",
+			SampleRate:    1,
+			RedactHeaders: "Authorization,Proxy-Authorization,Cookie,Set-Cookie,X-Api-Key",
+		},
+		Metrics: MetricsConfig{
+			Enabled: true,
+			Path:    "/metrics",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			ServiceName: "belajar-golang-fiber",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load membaca konfigurasi dengan urutan prioritas (yang belakang menimpa yang depan):
+// default => file YAML => environment variable => flag command line
+func Load(args []string) (*Config, error) {
+	config, _, err := LoadWithArgs(args)
+	return config, err
+}
+
+// LoadWithArgs sama seperti Load tetapi juga mengembalikan argumen sisa setelah flag
+func LoadWithArgs(args []string) (*Config, []string, error) {
+	config := Default()
+	fields := fieldsOf(config)
+
+	flagSet := flag.NewFlagSet("app", flag.ContinueOnError)
+	file := flagSet.String("config", "", "lokasi file konfigurasi YAML (env "+EnvPrefix+"CONFIG)")
+	values := map[string]*string{}
+	for _, field := range fields {
+		values[field.key] = flagSet.String(field.key, "", field.usage)
+	}
+	if err := flagSet.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	path, required := *file, true
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path, required = DefaultFile, false
+	}
+	if err := loadFile(config, path, required); err != nil {
+		return nil, nil, err
+	}
+
+	for _, field := range fields {
+		if value, ok := os.LookupEnv(field.env()); ok {
+			if err := field.set(value); err != nil {
+				return nil, nil, fmt.Errorf("config: env %s: %w", field.env(), err)
+			}
+		}
+	}
+
+	var flagErr error
+	flagSet.Visit(func(f *flag.Flag) {
+		for _, field := range fields {
+			if field.key == f.Name && flagErr == nil {
+				if err := field.set(*values[field.key]); err != nil {
+					flagErr = fmt.Errorf("config: flag -%s: %w", field.key, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, nil, flagErr
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return config, flagSet.Args(), nil
+}
+
+func loadFile(config *Config, path string, required bool) error {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate dipanggil saat startup supaya konfigurasi yang salah langsung ketahuan
+func (c *Config) Validate() error {
+	var errs []error
+	if c.Server.Address == "" {
+		errs = append(errs, errors.New("server.address is required"))
+	}
+	if c.Server.IdleTimeout <= 0 || c.Server.ReadTimeout <= 0 || c.Server.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("server timeouts must be greater than 0"))
+	}
+	if c.Database.Host == "" {
+		errs = append(errs, errors.New("database.host is required"))
+	}
+	if c.Database.Port <= 0 || c.Database.Port > 65535 {
+		errs = append(errs, errors.New("database.port must be between 1 and 65535"))
+	}
+	if c.Database.User == "" {
+		errs = append(errs, errors.New("database.user is required"))
+	}
+	if c.Database.Name == "" {
+		errs = append(errs, errors.New("database.name is required"))
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database connection pool sizes must not be negative"))
+	}
+	switch c.Database.LogLevel {
+	case "silent", "error", "warn", "info":
+	default:
+		errs = append(errs, errors.New("database.log_level must be one of silent, error, warn, info"))
+	}
+	if c.Database.SlowThreshold < 0 {
+		errs = append(errs, errors.New("database.slow_threshold must not be negative"))
+	}
+	if c.Security.PasswordCost < 4 || c.Security.PasswordCost > 31 {
+		errs = append(errs, errors.New("security.password_cost must be between 4 and 31"))
+	}
+	if c.Security.SessionExpiration <= 0 {
+		errs = append(errs, errors.New("security.session_expiration must be greater than 0"))
+	}
+
+	if c.Server.BodyLimit <= 0 {
+		errs = append(errs, errors.New("server.body_limit must be greater than 0"))
+	}
+	if c.Upload.MaxSize <= 0 || c.Upload.MaxFiles <= 0 {
+		errs = append(errs, errors.New("upload.max_size and upload.max_files must be greater than 0"))
+	}
+	if len(c.Upload.Types()) == 0 {
+		errs = append(errs, errors.New("upload.allowed_types must not be empty"))
+	}
+	if c.Upload.Expiration <= 0 {
+		errs = append(errs, errors.New("upload.expiration must be greater than 0"))
+	}
+	if c.Upload.PurgeInterval < 0 {
+		errs = append(errs, errors.New("upload.purge_interval must not be negative"))
+	}
+	switch c.Storage.Driver {
+	case "local":
+		if c.Storage.Dir == "" || c.Storage.DownloadURL == "" {
+			errs = append(errs, errors.New("storage.dir and storage.download_url are required for driver local"))
+		}
+	case "s3":
+		if c.Storage.Endpoint == "" || c.Storage.Region == "" || c.Storage.Bucket == "" || c.Storage.AccessKey == "" || c.Storage.SecretKey == "" {
+			errs = append(errs, errors.New("storage.endpoint, region, bucket, access_key and secret_key are required for driver s3"))
+		}
+	default:
+		errs = append(errs, errors.New("storage.driver must be one of local, s3"))
+	}
+	// batas maksimal presigned URL S3 adalah 7 hari
+	if c.Storage.SignedURLTTL <= 0 || c.Storage.SignedURLTTL > 7*24*time.Hour {
+		errs = append(errs, errors.New("storage.signed_url_ttl must be between 1s and 168h"))
+	}
+	if c.Users.DeletedRetention <= 0 {
+		errs = append(errs, errors.New("users.deleted_retention must be greater than 0"))
+	}
+	if c.Users.PurgeInterval < 0 {
+		errs = append(errs, errors.New("users.purge_interval must not be negative"))
+	}
+	switch c.Log.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, errors.New("log.level must be one of debug, info, warn, error"))
+	}
+	switch c.Log.Format {
+	case "json", "text":
+	default:
+		errs = append(errs, errors.New("log.format must be one of json, text"))
+	}
+	if c.Log.SampleRate < 0 || c.Log.SampleRate > 1 {
+		errs = append(errs, errors.New("log.sample_rate must be between 0 and 1"))
+	}
+	if c.Metrics.Enabled && !strings.HasPrefix(c.Metrics.Path, "/") {
+		errs = append(errs, errors.New("metrics.path must start with /"))
+	}
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	default:
+		errs = append(errs, errors.New("tracing.exporter must be one of none, otlp, stdout"))
+	}
+	if c.Tracing.Exporter != "none" && c.Tracing.ServiceName == "" {
+		errs = append(errs, errors.New("tracing.service_name is required"))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("tracing.sample_ratio must be between 0 and 1"))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("config: invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// String menampilkan konfigurasi dalam format YAML dengan nilai rahasia disamarkan
+func (c *Config) String() string {
+	redacted := *c
+	for _, field := range fieldsOf(&redacted) {
+		if field.secret && !field.value.IsZero() {
+			field.value.SetString("******")
+		}
+	}
+
+	content, err := yaml.Marshal(&redacted)
+	if err != nil {
+		return err.Error()
+	}
+	return string(content)
+}
+
+type field struct {
+	key    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+func (f field) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+}
+
+func (f field) set(value string) error {
+	switch f.value.Interface().(type) {
+	case string:
+		f.value.SetString(value)
+	case time.Duration:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(duration))
+	case int:
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		f.value.SetInt(int64(number))
+	case float64:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		f.value.SetFloat(number)
+	case bool:
+		boolean, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		f.value.SetBool(boolean)
+	default:
+		return fmt.Errorf("unsupported type %s", f.value.Type())
+	}
+	return nil
+}
+
+// fieldsOf mengambil semua field konfigurasi dengan key sesuai tag yaml, contoh "database.password"
+func fieldsOf(config *Config) []field {
+	var fields []field
+	root := reflect.ValueOf(config).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		sectionKey := root.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			structField := section.Type().Field(j)
+			fields = append(fields, field{
+				key:    sectionKey + "." + structField.Tag.Get("yaml"),
+				usage:  structField.Tag.Get("usage"),
+				secret: structField.Tag.Get("secret") == "true",
+				value:  section.Field(j),
+			})
+		}
+	}
+	return fields
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigPrecedence(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(file, []byte(`
+server:
+  address: localhost:8080
+  read_timeout: 10s
+database:
+  port: 3307
+  password: dari-file
+`), 0644)
+	assert.Nil(t, err)
+
+	t.Setenv("APP_DATABASE_PORT", "3308")
+	t.Setenv("APP_DATABASE_PASSWORD", "dari-env")
+
+	cfg, err := config.Load([]string{"-config", file, "-database.password", "dari-flag"})
+	assert.Nil(t, err)
+
+	assert.Equal(t, "localhost:8080", cfg.Server.Address)   // file
+	assert.Equal(t, 10*time.Second, cfg.Server.ReadTimeout) // file
+	assert.Equal(t, 5*time.Second, cfg.Server.WriteTimeout) // default
+	assert.Equal(t, 3308, cfg.Database.Port)                // env menimpa file
+	assert.Equal(t, "dari-flag", cfg.Database.Password)     // flag menimpa env
+	assert.Equal(t, "root:dari-flag@tcp(127.0.0.1:3308)/belajar_golang_gorm?charset=utf8mb4&parseTime=True&loc=Local", cfg.Database.DSN())
+}
+
+func TestConfigValidation(t *testing.T) {
+	_, err := config.Load([]string{"-database.port", "0", "-database.log_level", "debug"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "database.port")
+	assert.Contains(t, err.Error(), "database.log_level")
+
+	_, err = config.Load([]string{"-server.read_timeout", "sebentar"})
+	assert.NotNil(t, err)
+
+	_, err = config.Load([]string{"-config", filepath.Join(t.TempDir(), "tidak-ada.yaml")})
+	assert.NotNil(t, err)
+	// exporter memory menyimpan span tanpa batas, hanya untuk test lewat tracetest
+	_, err = config.Load([]string{"-tracing.exporter", "memory"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "tracing.exporter")
+}
+
+func TestConfigRedacted(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Password = "sangat-rahasia"
+
+	printed := cfg.String()
+	assert.NotContains(t, printed, "sangat-rahasia")
+	assert.Contains(t, printed, "password: '******'")
+	assert.Contains(t, printed, "read_timeout: 5s")
+	assert.Equal(t, "sangat-rahasia", cfg.Database.Password)
+}
+
+func TestConfigLog(t *testing.T) {
+	cfg, err := config.Load([]string{"-log.sample_rate", "0.25", "-log.redact_headers", "Authorization, X-Token"})
+	assert.Nil(t, err)
+	assert.Equal(t, 0.25, cfg.Log.SampleRate)
+	assert.Equal(t, []string{"Authorization", "X-Token"}, cfg.Log.Redacted())
+
+	_, err = config.Load([]string{"-log.sample_rate", "2", "-log.format", "xml"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "log.sample_rate")
+	assert.Contains(t, err.Error(), "log.format")
+}
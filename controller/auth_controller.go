@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"net/url"
+	"strings"
+
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+)
+
+type AuthController struct {
+	Service   service.AuthService
+	Store     *session.Store
+	Validator *validation.Validator
+}
+
+func NewAuthController(authService service.AuthService, store *session.Store, validator *validation.Validator) *AuthController {
+	return &AuthController{Service: authService, Store: store, Validator: validator}
+}
+
+func (c *AuthController) Route(router fiber.Router) {
+	router.Post("/register", c.Register)
+	router.Post("/login", c.Login)
+	router.Post("/logout", c.Logout)
+	router.Get("/me", middleware.NewAuth(c.Store), c.Me)
+}
+
+func (c *AuthController) Register(ctx *fiber.Ctx) error {
+	request := new(model.RegisterRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	response, err := c.Service.Register(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return sendRegisterResponse(ctx, *response)
+}
+
+// sendRegisterResponse membalas dengan format yang sama dengan body yang dikirim client (JSON, XML atau form)
+func sendRegisterResponse(ctx *fiber.Ctx, response model.RegisterResponse) error {
+	ctx.Status(fiber.StatusCreated)
+
+	contentType := strings.ToLower(ctx.Get(fiber.HeaderContentType))
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationXML), strings.HasPrefix(contentType, fiber.MIMETextXML):
+		return ctx.XML(response)
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationForm), strings.HasPrefix(contentType, fiber.MIMEMultipartForm):
+		ctx.Set(fiber.HeaderContentType, fiber.MIMEApplicationForm)
+		return ctx.SendString(url.Values{
+			"username":    {response.Username},
+			"first_name":  {response.FirstName},
+			"middle_name": {response.MiddleName},
+			"last_name":   {response.LastName},
+		}.Encode())
+	default:
+		return ctx.JSON(model.WebResponse[model.RegisterResponse]{Data: response})
+	}
+}
+
+func (c *AuthController) Login(ctx *fiber.Ctx) error {
+	request := new(model.LoginRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	response, err := c.Service.Login(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	sess, err := c.Store.Get(ctx)
+	if err != nil {
+		return err
+	}
+	// ganti session ID setiap login untuk mencegah session fixation
+	if err := sess.Regenerate(); err != nil {
+		return err
+	}
+	sess.Set(middleware.SessionUserKey, response.ID)
+	if err := sess.Save(); err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+func (c *AuthController) Logout(ctx *fiber.Ctx) error {
+	sess, err := c.Store.Get(ctx)
+	if err != nil {
+		return err
+	}
+	if err := sess.Destroy(); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *AuthController) Me(ctx *fiber.Ctx) error {
+	response, err := c.Service.Current(ctx.UserContext(), middleware.CurrentUserId(ctx))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
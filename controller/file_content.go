@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"belajar-golang-fiber/model"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxRanges membatasi jumlah range dalam satu request, lebih dari itu header Range diabaikan
+const maxRanges = 16
+
+var errUnsatisfiableRange = errors.New("range not satisfiable")
+
+type byteRange struct {
+	start  int64
+	length int64
+}
+
+func (r byteRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+// sendContent mengirim isi file sebagai attachment dengan dukungan conditional request
+// (ETag dari checksum dan Last-Modified => 304) serta Range (206, multi range => multipart/byteranges)
+func sendContent(ctx *fiber.Ctx, content *model.FileContent) error {
+	etag := `"` + content.Checksum + `"`
+	modified := content.CreatedAt.UTC().Truncate(time.Second)
+
+	ctx.Set(fiber.HeaderETag, etag)
+	ctx.Set(fiber.HeaderLastModified, modified.Format(http.TimeFormat))
+	ctx.Set(fiber.HeaderAcceptRanges, "bytes")
+	ctx.Set(fiber.HeaderContentDisposition, mime.FormatMediaType("attachment", map[string]string{"filename": content.Name}))
+	ctx.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+
+	if notModified(ctx, etag, modified) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+
+	ranges, err := parseRange(ctx.Get(fiber.HeaderRange), content.Size)
+	if ifRange := ctx.Get(fiber.HeaderIfRange); ifRange != "" && !ifRangeMatches(ifRange, etag, modified) {
+		// file sudah berubah dari yang dimiliki client, kirim ulang seluruhnya
+		ranges, err = nil, nil
+	}
+	if err != nil {
+		ctx.Set(fiber.HeaderContentRange, fmt.Sprintf("bytes */%d", content.Size))
+		return fiber.NewError(fiber.StatusRequestedRangeNotSatisfiable, err.Error())
+	}
+
+	switch len(ranges) {
+	case 0:
+		reader, err := content.Open(0, content.Size)
+		if err != nil {
+			return err
+		}
+		ctx.Set(fiber.HeaderContentType, content.ContentType)
+		// reader ditutup oleh fasthttp setelah response selesai dikirim
+		return ctx.SendStream(reader, int(content.Size))
+	case 1:
+		reader, err := content.Open(ranges[0].start, ranges[0].length)
+		if err != nil {
+			return err
+		}
+		ctx.Set(fiber.HeaderContentType, content.ContentType)
+		ctx.Set(fiber.HeaderContentRange, ranges[0].contentRange(content.Size))
+		return ctx.Status(fiber.StatusPartialContent).SendStream(reader, int(ranges[0].length))
+	default:
+		return sendRanges(ctx, content, ranges)
+	}
+}
+
+// sendRanges mengirim beberapa range sebagai multipart/byteranges, setiap part dibaca dari storage
+// saat body dikirim sehingga file tidak perlu dimuat ke memory
+func sendRanges(ctx *fiber.Ctx, content *model.FileContent, ranges []byteRange) error {
+	reader, writer := io.Pipe()
+	parts := multipart.NewWriter(writer)
+
+	go func() {
+		writer.CloseWithError(writeRanges(parts, content, ranges))
+	}()
+
+	ctx.Set(fiber.HeaderContentType, "multipart/byteranges; boundary="+parts.Boundary())
+	return ctx.Status(fiber.StatusPartialContent).SendStream(&rangesBody{reader})
+}
+
+// writeRanges berhenti di write berikutnya begitu rangesBody ditutup, section yang sedang dibaca ikut ditutup
+func writeRanges(parts *multipart.Writer, content *model.FileContent, ranges []byteRange) error {
+	for _, r := range ranges {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			fiber.HeaderContentType:  {content.ContentType},
+			fiber.HeaderContentRange: {r.contentRange(content.Size)},
+		})
+		if err != nil {
+			return err
+		}
+
+		section, err := content.Open(r.start, r.length)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(part, section)
+		section.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return parts.Close()
+}
+
+var errRangesAborted = errors.New("multipart/byteranges response aborted")
+
+// rangesBody ditutup fasthttp setelah response selesai dikirim maupun saat client memutus koneksi
+// di tengah jalan. Pipe ditutup dengan error supaya goroutine penulis tidak terblokir selamanya
+type rangesBody struct {
+	reader *io.PipeReader
+}
+
+func (b *rangesBody) Read(p []byte) (int, error) {
+	return b.reader.Read(p)
+}
+
+func (b *rangesBody) Close() error {
+	return b.reader.CloseWithError(errRangesAborted)
+}
+
+// notModified mengecek If-None-Match lebih dulu, If-Modified-Since hanya dipakai jika tidak ada ETag dari client
+func notModified(ctx *fiber.Ctx, etag string, modified time.Time) bool {
+	if match := ctx.Get(fiber.HeaderIfNoneMatch); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := ctx.Get(fiber.HeaderIfModifiedSince); since != "" {
+		sinceTime, err := http.ParseTime(since)
+		return err == nil && !modified.After(sinceTime)
+	}
+	return false
+}
+
+// ifRangeMatches memakai perbandingan strong, ETag weak tidak pernah cocok
+func ifRangeMatches(ifRange string, etag string, modified time.Time) bool {
+	if strings.HasPrefix(ifRange, `"`) || strings.HasPrefix(ifRange, "W/") {
+		return ifRange == etag
+	}
+	ifRangeTime, err := http.ParseTime(ifRange)
+	return err == nil && modified.Equal(ifRangeTime)
+}
+
+// parseRange membaca header Range (contoh bytes=0-99,200-,-500). Header yang tidak valid atau
+// terlalu banyak range diabaikan (nil, nil) sehingga file dikirim utuh
+func parseRange(header string, size int64) ([]byteRange, error) {
+	specs, ok := strings.CutPrefix(header, "bytes=")
+	if header == "" || !ok {
+		return nil, nil
+	}
+
+	var ranges []byteRange
+	specList := strings.Split(specs, ",")
+	if len(specList) > maxRanges {
+		return nil, nil
+	}
+	for _, spec := range specList {
+		first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+		if !ok {
+			return nil, nil
+		}
+
+		var r byteRange
+		if first == "" {
+			// suffix range: N byte terakhir
+			suffix, err := strconv.ParseInt(last, 10, 64)
+			if err != nil || suffix <= 0 {
+				return nil, nil
+			}
+			if suffix > size {
+				suffix = size
+			}
+			r = byteRange{start: size - suffix, length: suffix}
+		} else {
+			start, err := strconv.ParseInt(first, 10, 64)
+			if err != nil || start < 0 {
+				return nil, nil
+			}
+			end := size - 1
+			if last != "" {
+				if end, err = strconv.ParseInt(last, 10, 64); err != nil || end < start {
+					return nil, nil
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			r = byteRange{start: start, length: end - start + 1}
+		}
+
+		// range di luar ukuran file dilewati, jika semuanya di luar maka 416
+		if r.start >= size || r.length <= 0 {
+			continue
+		}
+		ranges = append(ranges, r)
+	}
+
+	if len(ranges) == 0 {
+		return nil, errUnsatisfiableRange
+	}
+	return ranges, nil
+}
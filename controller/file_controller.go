@@ -0,0 +1,145 @@
+package controller
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"sort"
+	"strings"
+
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FileController struct {
+	Service service.FileService
+}
+
+func NewFileController(fileService service.FileService) *FileController {
+	return &FileController{Service: fileService}
+}
+
+// Route mendaftarkan endpoint /files pada router, membutuhkan middleware NewAuth dan NewPermissions.
+// File hanya bisa dibaca uploader-nya atau user dengan permission files:admin.
+// /download/:fileId tidak butuh login, aksesnya dicek dari tanda tangan URL
+func (c *FileController) Route(router fiber.Router) {
+	files := router.Group("/files")
+	files.Post("/", c.Upload)
+	files.Get("/:fileId", c.Get)
+	files.Get("/:fileId/content", c.Download)
+	files.Get("/:fileId/url", c.DownloadURL)
+	router.Get("/download/:fileId", c.SignedDownload)
+}
+
+// Upload menerima multipart/form-data (boleh banyak file) atau body mentah yang di-stream
+// dengan nama file di query ?name=contoh.txt
+func (c *FileController) Upload(ctx *fiber.Ctx) error {
+	var files []model.UploadFile
+	if strings.HasPrefix(ctx.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		form, err := ctx.MultipartForm()
+		if err != nil {
+			return fiber.ErrBadRequest
+		}
+
+		headers := formFiles(form)
+		for _, header := range headers {
+			file, err := header.Open()
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			files = append(files, model.UploadFile{Name: header.Filename, Reader: file})
+		}
+	} else {
+		var body io.Reader = ctx.Request().BodyStream()
+		if body == nil {
+			body = bytes.NewReader(ctx.Body())
+		}
+		files = append(files, model.UploadFile{Name: ctx.Query("name"), Reader: body})
+	}
+
+	responses, err := c.Service.Upload(ctx.UserContext(), middleware.CurrentUserId(ctx), files...)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[[]model.FileResponse]{Data: responses})
+}
+
+func (c *FileController) Get(ctx *fiber.Ctx) error {
+	response, err := c.authorize(ctx)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.FileResponse]{Data: response})
+}
+
+// Download mengirim isi file sebagai attachment, mendukung Range dan conditional request
+func (c *FileController) Download(ctx *fiber.Ctx) error {
+	content, err := c.Service.Open(ctx.UserContext(), ctx.Params("fileId"))
+	if err != nil {
+		return err
+	}
+	// isi file baru dibaca dari storage di sendContent, setelah pemiliknya dicek
+	if err := middleware.Authorize(ctx, security.PermFilesAdmin, content.UploadedBy); err != nil {
+		return err
+	}
+
+	return sendContent(ctx, content)
+}
+
+// DownloadURL membuat URL download sementara, untuk storage S3 URL-nya langsung ke bucket.
+// Siapa pun yang memegang URL tersebut bisa mengunduh, jadi pemiliknya dicek sebelum URL dibuat
+func (c *FileController) DownloadURL(ctx *fiber.Ctx) error {
+	if _, err := c.authorize(ctx); err != nil {
+		return err
+	}
+
+	response, err := c.Service.DownloadURL(ctx.UserContext(), ctx.Params("fileId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.DownloadURLResponse]{Data: response})
+}
+
+func (c *FileController) SignedDownload(ctx *fiber.Ctx) error {
+	content, err := c.Service.OpenSigned(ctx.UserContext(), ctx.Params("fileId"), ctx.Query("expires"), ctx.Query("signature"))
+	if err != nil {
+		return err
+	}
+
+	return sendContent(ctx, content)
+}
+
+// authorize mengambil metadata file dan mengizinkan uploader-nya atau user dengan permission files:admin
+func (c *FileController) authorize(ctx *fiber.Ctx) (*model.FileResponse, error) {
+	response, err := c.Service.Get(ctx.UserContext(), ctx.Params("fileId"))
+	if err != nil {
+		return nil, err
+	}
+	if err := middleware.Authorize(ctx, security.PermFilesAdmin, response.UploadedBy); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// formFiles mengambil semua file dari form dengan urutan nama field yang tetap
+func formFiles(form *multipart.Form) []*multipart.FileHeader {
+	fields := make([]string, 0, len(form.File))
+	for field := range form.File {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var headers []*multipart.FileHeader
+	for _, field := range fields {
+		headers = append(headers, form.File[field]...)
+	}
+	return headers
+}
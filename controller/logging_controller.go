@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"log/slog"
+	"strings"
+
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoggingController mengubah level log tanpa restart aplikasi, contoh mengaktifkan log query
+// sementara saat mencari masalah di production
+type LoggingController struct {
+	Level     *slog.LevelVar
+	Database  *database.Logger
+	Validator *validation.Validator
+}
+
+func NewLoggingController(level *slog.LevelVar, databaseLogger *database.Logger, validator *validation.Validator) *LoggingController {
+	return &LoggingController{Level: level, Database: databaseLogger, Validator: validator}
+}
+
+// Route mendaftarkan endpoint /admin/logging, dijaga dengan permission logs:manage di main
+func (c *LoggingController) Route(router fiber.Router) {
+	router.Get("/admin/logging", c.Get)
+	router.Put("/admin/logging", c.Update)
+}
+
+func (c *LoggingController) Get(ctx *fiber.Ctx) error {
+	return ctx.JSON(model.WebResponse[model.LoggingResponse]{Data: c.response()})
+}
+
+func (c *LoggingController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateLoggingRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	if request.Level != "" {
+		if err := c.Level.UnmarshalText([]byte(request.Level)); err != nil {
+			return err
+		}
+	}
+	if request.DatabaseLevel != "" {
+		if err := c.Database.SetLevel(request.DatabaseLevel); err != nil {
+			return err
+		}
+	}
+	slog.InfoContext(ctx.UserContext(), "log level changed", slog.String("level", request.Level), slog.String("database_level", request.DatabaseLevel))
+
+	return ctx.JSON(model.WebResponse[model.LoggingResponse]{Data: c.response()})
+}
+
+func (c *LoggingController) response() model.LoggingResponse {
+	return model.LoggingResponse{
+		Level:         strings.ToLower(c.Level.Level().String()),
+		DatabaseLevel: c.Database.Level(),
+	}
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/query"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseQuery membaca pagination, sort dan filter dari query string sesuai whitelist schema
+func parseQuery(ctx *fiber.Ctx, schema *query.Schema) (*query.Query, error) {
+	values, err := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+	if err != nil {
+		return nil, fiber.ErrBadRequest
+	}
+	return schema.Parse(values)
+}
+
+// setLinkHeader menambahkan header Link (RFC 8288) untuk navigasi halaman, parameter lain
+// seperti filter dan sort tetap dipertahankan
+func setLinkHeader(ctx *fiber.Ctx, paging *model.PageMetadata) {
+	values, _ := url.ParseQuery(string(ctx.Request().URI().QueryString()))
+	link := func(rel string, change func(values url.Values)) string {
+		next := url.Values{}
+		for key, value := range values {
+			next[key] = value
+		}
+		change(next)
+		return fmt.Sprintf(`<%s%s?%s>; rel="%s"`, ctx.BaseURL(), ctx.Path(), next.Encode(), rel)
+	}
+	page := func(number int64) func(values url.Values) {
+		return func(values url.Values) {
+			values.Del(query.ParamCursor)
+			values.Set(query.ParamPage, strconv.FormatInt(number, 10))
+		}
+	}
+
+	var links []string
+	if paging.Page > 0 {
+		links = append(links, link("first", page(1)))
+		if paging.Page > 1 {
+			links = append(links, link("prev", page(int64(paging.Page)-1)))
+		}
+		if int64(paging.Page) < paging.TotalPage {
+			links = append(links, link("next", page(int64(paging.Page)+1)))
+		}
+		if paging.TotalPage > 0 {
+			links = append(links, link("last", page(paging.TotalPage)))
+		}
+	} else if paging.NextCursor != "" {
+		links = append(links, link("next", func(values url.Values) {
+			values.Del(query.ParamPage)
+			values.Set(query.ParamCursor, paging.NextCursor)
+		}))
+	}
+
+	if len(links) > 0 {
+		ctx.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
@@ -0,0 +1,15 @@
+package controller
+
+import (
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseRequest membaca body (JSON, form atau XML) lalu langsung memvalidasi hasilnya
+func parseRequest(ctx *fiber.Ctx, validator *validation.Validator, request any) error {
+	if err := ctx.BodyParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+	return validator.Struct(request)
+}
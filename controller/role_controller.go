@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type RoleController struct {
+	Service   service.RoleService
+	Validator *validation.Validator
+}
+
+func NewRoleController(roleService service.RoleService, validator *validation.Validator) *RoleController {
+	return &RoleController{Service: roleService, Validator: validator}
+}
+
+// Route mendaftarkan endpoint admin /roles, group ini dijaga dengan permission roles:manage di main
+func (c *RoleController) Route(router fiber.Router) {
+	roles := router.Group("/roles")
+	roles.Get("/", c.List)
+	roles.Post("/", c.Create)
+	roles.Get("/:roleId", c.Get)
+	roles.Put("/:roleId", c.Update)
+	roles.Delete("/:roleId", c.Delete)
+	roles.Put("/:roleId/users/:userId", c.Assign)
+	roles.Delete("/:roleId/users/:userId", c.Revoke)
+}
+
+// List menampilkan semua role, ?user_id= untuk role milik satu user
+func (c *RoleController) List(ctx *fiber.Ctx) error {
+	responses, err := c.Service.List(ctx.UserContext(), ctx.Query("user_id"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.RoleResponse]{Data: responses})
+}
+
+func (c *RoleController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateRoleRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	response, err := c.Service.Create(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.RoleResponse]{Data: response})
+}
+
+func (c *RoleController) Get(ctx *fiber.Ctx) error {
+	response, err := c.Service.Get(ctx.UserContext(), ctx.Params("roleId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.RoleResponse]{Data: response})
+}
+
+func (c *RoleController) Update(ctx *fiber.Ctx) error {
+	request := new(model.UpdateRoleRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	response, err := c.Service.Update(ctx.UserContext(), ctx.Params("roleId"), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.RoleResponse]{Data: response})
+}
+
+func (c *RoleController) Delete(ctx *fiber.Ctx) error {
+	if err := c.Service.Delete(ctx.UserContext(), ctx.Params("roleId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *RoleController) Assign(ctx *fiber.Ctx) error {
+	if err := c.Service.Assign(ctx.UserContext(), ctx.Params("userId"), ctx.Params("roleId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *RoleController) Revoke(ctx *fiber.Ctx) error {
+	if err := c.Service.Revoke(ctx.UserContext(), ctx.Params("userId"), ctx.Params("roleId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
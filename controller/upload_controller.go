@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	TusVersion    = "1.0.0"
+	TusExtensions = "creation,termination,expiration"
+
+	HeaderTusResumable   = "Tus-Resumable"
+	HeaderTusVersion     = "Tus-Version"
+	HeaderTusExtension   = "Tus-Extension"
+	HeaderTusMaxSize     = "Tus-Max-Size"
+	HeaderUploadLength   = "Upload-Length"
+	HeaderUploadOffset   = "Upload-Offset"
+	HeaderUploadMetadata = "Upload-Metadata"
+	HeaderUploadExpires  = "Upload-Expires"
+	// HeaderFileId dikirim saat upload selesai, berisi ID file untuk endpoint /files
+	HeaderFileId = "X-File-Id"
+
+	MIMEOffsetOctetStream = "application/offset+octet-stream"
+)
+
+// UploadController adalah endpoint upload bertahap dengan protokol tus 1.0.0 (core, creation, termination, expiration)
+type UploadController struct {
+	Service service.UploadService
+	MaxSize int
+}
+
+func NewUploadController(uploadService service.UploadService, maxSize int) *UploadController {
+	return &UploadController{Service: uploadService, MaxSize: maxSize}
+}
+
+// Route mendaftarkan endpoint /uploads pada router, membutuhkan middleware NewAuth
+func (c *UploadController) Route(router fiber.Router) {
+	uploads := router.Group("/uploads", tusResumable)
+	uploads.Options("/", c.Options)
+	uploads.Post("/", c.Create)
+	uploads.Head("/:uploadId", c.Head)
+	uploads.Patch("/:uploadId", c.Patch)
+	uploads.Delete("/:uploadId", c.Delete)
+}
+
+// tusResumable mewajibkan header Tus-Resumable yang didukung, kecuali untuk OPTIONS
+func tusResumable(ctx *fiber.Ctx) error {
+	ctx.Set(HeaderTusResumable, TusVersion)
+	if ctx.Method() != fiber.MethodOptions && ctx.Get(HeaderTusResumable) != TusVersion {
+		ctx.Set(HeaderTusVersion, TusVersion)
+		return fiber.NewError(fiber.StatusPreconditionFailed, "unsupported tus version")
+	}
+	return ctx.Next()
+}
+
+func (c *UploadController) Options(ctx *fiber.Ctx) error {
+	ctx.Set(HeaderTusVersion, TusVersion)
+	ctx.Set(HeaderTusExtension, TusExtensions)
+	ctx.Set(HeaderTusMaxSize, strconv.Itoa(c.MaxSize))
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *UploadController) Create(ctx *fiber.Ctx) error {
+	length, err := strconv.ParseInt(ctx.Get(HeaderUploadLength), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid Upload-Length header")
+	}
+
+	request := &model.CreateUploadRequest{
+		Name:   parseUploadMetadata(ctx.Get(HeaderUploadMetadata))["filename"],
+		Length: length,
+	}
+	response, err := c.Service.Create(ctx.UserContext(), middleware.CurrentUserId(ctx), request)
+	if err != nil {
+		return err
+	}
+
+	ctx.Location(strings.TrimSuffix(ctx.Path(), "/") + "/" + response.ID)
+	ctx.Set(HeaderUploadOffset, "0")
+	setUploadExpires(ctx, response)
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.UploadResponse]{Data: response})
+}
+
+// Head dipakai client untuk mengetahui offset terakhir sebelum melanjutkan upload
+func (c *UploadController) Head(ctx *fiber.Ctx) error {
+	response, err := c.Service.Get(ctx.UserContext(), middleware.CurrentUserId(ctx), ctx.Params("uploadId"))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(HeaderUploadOffset, strconv.FormatInt(response.Offset, 10))
+	ctx.Set(HeaderUploadLength, strconv.FormatInt(response.Length, 10))
+	setUploadExpires(ctx, response)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.SendStatus(fiber.StatusOK)
+}
+
+func (c *UploadController) Patch(ctx *fiber.Ctx) error {
+	if ctx.Get(fiber.HeaderContentType) != MIMEOffsetOctetStream {
+		return fiber.NewError(fiber.StatusUnsupportedMediaType, "content type must be "+MIMEOffsetOctetStream)
+	}
+	offset, err := strconv.ParseInt(ctx.Get(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid Upload-Offset header")
+	}
+
+	var body io.Reader = ctx.Request().BodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.Body())
+	}
+	response, err := c.Service.Append(ctx.UserContext(), middleware.CurrentUserId(ctx), ctx.Params("uploadId"), offset, body)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(HeaderUploadOffset, strconv.FormatInt(response.Offset, 10))
+	if response.File != nil {
+		ctx.Set(HeaderFileId, response.File.ID)
+	} else {
+		setUploadExpires(ctx, response)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func (c *UploadController) Delete(ctx *fiber.Ctx) error {
+	if err := c.Service.Delete(ctx.UserContext(), middleware.CurrentUserId(ctx), ctx.Params("uploadId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// setUploadExpires mengirim batas waktu melanjutkan upload dalam format tanggal HTTP (RFC 9110)
+func setUploadExpires(ctx *fiber.Ctx, response *model.UploadResponse) {
+	ctx.Set(HeaderUploadExpires, response.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parseUploadMetadata membaca header Upload-Metadata, contoh "filename Y29udG9oLnR4dA==,private"
+func parseUploadMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		metadata[key] = string(decoded)
+	}
+	return metadata
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"strconv"
+	"strings"
+
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserController struct {
+	Service   service.UserService
+	Validator *validation.Validator
+}
+
+func NewUserController(userService service.UserService, validator *validation.Validator) *UserController {
+	return &UserController{Service: userService, Validator: validator}
+}
+
+// Route mendaftarkan endpoint /users pada router (biasanya group /api), membutuhkan middleware
+// NewAuth dan NewPermissions karena setiap route dicek permission-nya
+func (c *UserController) Route(router fiber.Router) {
+	read := middleware.Require(security.PermUsersRead)
+	users := router.Group("/users")
+	users.Post("/", middleware.Require(security.PermUsersWrite), c.Create)
+	users.Get("/", read, c.List)
+	users.Get("/search", read, c.Search)
+	users.Get("/:userId", read, c.Get)
+	users.Patch("/:userId", c.Update)
+	users.Delete("/:userId", middleware.Require(security.PermUsersWrite), c.Delete)
+	users.Post("/:userId/restore", middleware.Require(security.PermUsersAdmin), c.Restore)
+	users.Get("/:userId/logs", read, c.Logs)
+}
+
+func (c *UserController) Create(ctx *fiber.Ctx) error {
+	request := new(model.CreateUserRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	response, err := c.Service.Create(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, userETag(response.Version))
+	return ctx.Status(fiber.StatusCreated).JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+func (c *UserController) Get(ctx *fiber.Ctx) error {
+	response, err := c.Service.Get(ctx.UserContext(), ctx.Params("userId"))
+	if err != nil {
+		return err
+	}
+
+	etag := userETag(response.Version)
+	ctx.Set(fiber.HeaderETag, etag)
+	if notModified(ctx, etag, response.UpdatedAt) {
+		return ctx.SendStatus(fiber.StatusNotModified)
+	}
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+// List mendukung ?page, ?limit, ?cursor, ?sort=-first_name,id dan filter seperti ?first_name[like]=User%.
+// User dengan permission users:admin bisa menambahkan ?include_deleted=true untuk ikut menampilkan user yang sudah dihapus
+func (c *UserController) List(ctx *fiber.Ctx) error {
+	q, err := parseQuery(ctx, model.UserQuery)
+	if err != nil {
+		return err
+	}
+
+	includeDeleted := ctx.QueryBool("include_deleted")
+	if includeDeleted && !middleware.Can(ctx, security.PermUsersAdmin) {
+		return exception.Forbidden("include_deleted requires permission " + security.PermUsersAdmin)
+	}
+
+	responses, paging, err := c.Service.List(ctx.UserContext(), q, includeDeleted)
+	if err != nil {
+		return err
+	}
+
+	setLinkHeader(ctx, paging)
+	return ctx.JSON(model.WebResponse[[]model.UserResponse]{Data: responses, Paging: paging})
+}
+
+// Search mencari user berdasarkan nama depan, tengah dan belakang. Query: ?q=bagus wica&limit=20
+func (c *UserController) Search(ctx *fiber.Ctx) error {
+	request := new(model.SearchUserRequest)
+	if err := ctx.QueryParser(request); err != nil {
+		return fiber.ErrBadRequest
+	}
+	if err := c.Validator.Struct(request); err != nil {
+		return err
+	}
+
+	responses, err := c.Service.Search(ctx.UserContext(), request)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.UserSearchResponse]{Data: responses})
+}
+
+// Update mendukung header If-Match berisi ETag dari GET, 412 jika user sudah diubah oleh request lain.
+// User tanpa permission users:write hanya boleh mengubah dirinya sendiri
+func (c *UserController) Update(ctx *fiber.Ctx) error {
+	if err := middleware.Authorize(ctx, security.PermUsersWrite, ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	request := new(model.UpdateUserRequest)
+	if err := parseRequest(ctx, c.Validator, request); err != nil {
+		return err
+	}
+
+	// If-Match wajib supaya update tidak menimpa perubahan orang lain, If-Match: * berarti sengaja tanpa cek versi
+	ifMatch := ctx.Get(fiber.HeaderIfMatch)
+	if strings.TrimSpace(ifMatch) == "" {
+		return exception.PreconditionRequired("If-Match header with the user ETag is required")
+	}
+
+	response, err := c.Service.Update(ctx.UserContext(), ctx.Params("userId"), request, parseIfMatch(ifMatch))
+	if err != nil {
+		return err
+	}
+
+	ctx.Set(fiber.HeaderETag, userETag(response.Version))
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+func (c *UserController) Delete(ctx *fiber.Ctx) error {
+	if err := c.Service.Delete(ctx.UserContext(), ctx.Params("userId")); err != nil {
+		return err
+	}
+
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+// Restore mengembalikan user yang sudah dihapus, membutuhkan permission users:admin
+func (c *UserController) Restore(ctx *fiber.Ctx) error {
+	response, err := c.Service.Restore(ctx.UserContext(), ctx.Params("userId"))
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[*model.UserResponse]{Data: response})
+}
+
+// Logs menampilkan riwayat perubahan user, terbaru lebih dulu. Query: ?page=1&size=20
+func (c *UserController) Logs(ctx *fiber.Ctx) error {
+	page := ctx.QueryInt("page", 1)
+	size := ctx.QueryInt("size", 20)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 || size > 100 {
+		size = 20
+	}
+
+	responses, paging, err := c.Service.Logs(ctx.UserContext(), ctx.Params("userId"), page, size)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(model.WebResponse[[]model.UserLogResponse]{Data: responses, Paging: paging})
+}
+
+// userETag adalah ETag strong dari versi user
+func userETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// parseIfMatch mengubah header If-Match menjadi daftar versi. * berarti tanpa pengecekan versi (nil),
+// ETag weak atau yang tidak dikenal tidak pernah cocok
+func parseIfMatch(header string) []int64 {
+	if header = strings.TrimSpace(header); header == "" || header == "*" {
+		return nil
+	}
+
+	versions := []int64{}
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimSpace(etag)
+		if len(etag) < 2 || !strings.HasPrefix(etag, `"`) || !strings.HasSuffix(etag, `"`) {
+			continue
+		}
+		if version, err := strconv.ParseInt(etag[1:len(etag)-1], 10, 64); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	return versions
+}
@@ -0,0 +1,46 @@
+package database
+
+import (
+	"log/slog"
+
+	"belajar-golang-fiber/config"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var logLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// OpenConnection membuka koneksi GORM ke MySQL berdasarkan konfigurasi database
+func OpenConnection(cfg config.DatabaseConfig) (*gorm.DB, error) {
+	return Open(mysql.Open(cfg.DSN()), cfg)
+}
+
+// Open membuka koneksi dengan dialector apa saja (MySQL di aplikasi, SQLite di test)
+// dengan pengaturan GORM dan connection pool yang sama. Query dicatat lewat slog.Default(),
+// logger-nya bisa diambil kembali dengan db.Logger.(*database.Logger) untuk mengubah level
+func Open(dialector gorm.Dialector, cfg config.DatabaseConfig) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:         NewLogger(slog.Default(), cfg),
+		TranslateError: true, // error duplicate key dll diterjemahkan menjadi gorm.ErrDuplicatedKey
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	return db, nil
+}
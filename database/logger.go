@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// Logger adalah logger GORM yang menulis record terstruktur lewat slog (request_id ikut tercatat jika
+// logger dibuat dengan logging.New). Query yang lebih lama dari slow threshold dicatat sebagai warning
+// bersama lokasi pemanggilnya, dan nilai parameter untuk kolom sensitif (contoh password) disamarkan
+type Logger struct {
+	logger        *slog.Logger
+	level         *atomic.Int64
+	slowThreshold time.Duration
+	redacted      []string
+}
+
+func NewLogger(l *slog.Logger, cfg config.DatabaseConfig) *Logger {
+	level := new(atomic.Int64)
+	level.Store(int64(logLevels[cfg.LogLevel]))
+
+	var redacted []string
+	for _, column := range cfg.Redacted() {
+		redacted = append(redacted, strings.ToLower(column))
+	}
+	return &Logger{logger: l, level: level, slowThreshold: cfg.SlowThreshold, redacted: redacted}
+}
+
+// Level mengembalikan nama level yang sedang aktif (silent, error, warn, info)
+func (l *Logger) Level() string {
+	current := logger.LogLevel(l.level.Load())
+	for name, level := range logLevels {
+		if level == current {
+			return name
+		}
+	}
+	return ""
+}
+
+// SetLevel mengubah level saat aplikasi berjalan, berlaku untuk semua query berikutnya
+func (l *Logger) SetLevel(name string) error {
+	level, ok := logLevels[name]
+	if !ok {
+		return fmt.Errorf("unknown database log level %q", name)
+	}
+	l.level.Store(int64(level))
+	return nil
+}
+
+// LogMode dipanggil GORM misalnya oleh db.Debug(), level hasilnya terpisah dari logger asal
+func (l *Logger) LogMode(level logger.LogLevel) logger.Interface {
+	clone := *l
+	clone.level = new(atomic.Int64)
+	clone.level.Store(int64(level))
+	return &clone
+}
+
+func (l *Logger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Info, slog.LevelInfo, fmt.Sprintf(msg, data...))
+}
+
+func (l *Logger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Warn, slog.LevelWarn, fmt.Sprintf(msg, data...))
+}
+
+func (l *Logger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, logger.Error, slog.LevelError, fmt.Sprintf(msg, data...))
+}
+
+func (l *Logger) log(ctx context.Context, minimum logger.LogLevel, level slog.Level, msg string, attrs ...slog.Attr) {
+	if logger.LogLevel(l.level.Load()) < minimum {
+		return
+	}
+	l.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// Trace dipanggil GORM setelah setiap query, record not found tidak dianggap error
+func (l *Logger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	current := logger.LogLevel(l.level.Load())
+	if current <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	query := func(attrs ...slog.Attr) []slog.Attr {
+		sql, rows := fc()
+		return append([]slog.Attr{
+			slog.String("sql", sql),
+			slog.Int64("rows", rows),
+			slog.Duration("elapsed", elapsed),
+		}, attrs...)
+	}
+
+	switch {
+	case err != nil && current >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		l.logger.LogAttrs(ctx, slog.LevelError, "query failed", query(slog.String("caller", utils.FileWithLineNum()), slog.Any("error", err))...)
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold && current >= logger.Warn:
+		l.logger.LogAttrs(ctx, slog.LevelWarn, "slow query", query(slog.String("caller", utils.FileWithLineNum()), slog.Duration("threshold", l.slowThreshold))...)
+	case current >= logger.Info:
+		l.logger.LogAttrs(ctx, slog.LevelInfo, "query", query()...)
+	}
+}
+
+// ParamsFilter dipanggil GORM sebelum parameter dimasukkan ke SQL yang dicatat, nilai yang sebenarnya
+// dikirim ke database tidak berubah
+func (l *Logger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if len(l.redacted) == 0 || len(params) == 0 {
+		return sql, params
+	}
+
+	var filtered []interface{}
+	for i, column := range placeholderColumns(sql) {
+		if i < len(params) && slices.Contains(l.redacted, column) {
+			if filtered == nil {
+				filtered = slices.Clone(params)
+			}
+			filtered[i] = logging.Redacted
+		}
+	}
+	if filtered == nil {
+		return sql, params
+	}
+	return sql, filtered
+}
+
+// placeholderColumns mencari nama kolom untuk setiap placeholder ? dengan melihat teks sebelumnya
+// (`password` = ?) atau urutan kolom pada INSERT INTO t (a, b) VALUES (?, ?). Nama dikembalikan dalam
+// huruf kecil tanpa quote dan prefix tabel, kosong jika kolomnya tidak diketahui
+func placeholderColumns(sql string) []string {
+	var insertColumns []string
+	values := -1
+	upper := strings.ToUpper(sql)
+	if strings.HasPrefix(strings.TrimSpace(upper), "INSERT") {
+		open := strings.IndexByte(sql, '(')
+		values = strings.Index(upper, "VALUES")
+		if open >= 0 && open < values {
+			if closing := strings.IndexByte(sql[open:], ')'); closing > 0 {
+				for _, column := range strings.Split(sql[open+1:open+closing], ",") {
+					insertColumns = append(insertColumns, identifier(column))
+				}
+			}
+		}
+	}
+
+	var columns []string
+	var quote byte
+	position := 0
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			column := columnBefore(sql[:i])
+			if column == "" && len(insertColumns) > 0 && i > values {
+				column = insertColumns[position%len(insertColumns)]
+				position++
+			}
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+// columnBefore mengambil identifier sebelum operator perbandingan, contoh "WHERE `users`.`password` = "
+func columnBefore(prefix string) string {
+	trimmed := strings.TrimRight(prefix, " ")
+	withoutOperator := strings.TrimRight(trimmed, "=<>!")
+	if withoutOperator == trimmed {
+		return ""
+	}
+	withoutOperator = strings.TrimRight(withoutOperator, " ")
+
+	start := len(withoutOperator)
+	for start > 0 && isIdentifierChar(withoutOperator[start-1]) {
+		start--
+	}
+	return identifier(withoutOperator[start:])
+}
+
+func identifier(name string) string {
+	name = strings.TrimSpace(name)
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+	return strings.ToLower(strings.Trim(name, "`\""))
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '`' || c == '"' || c == '.' || c == '$' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
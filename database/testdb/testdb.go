@@ -0,0 +1,144 @@
+package testdb
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/migration"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// EnvDriver memilih database untuk test: kosong/sqlite (default) atau mysql.
+// Mode mysql memakai koneksi dari config (APP_DATABASE_*) dan schema dari `go run ./cmd/migrate up`
+const EnvDriver = "APP_TEST_DATABASE"
+
+// Fixture mengisi data awal test di dalam transaksi test
+type Fixture func(tx *gorm.DB) error
+
+// tabel yang perlu DDL khusus di SQLite, AutoMigrate tidak membuat kolom id string menjadi autoincrement
+var sqliteTables = []string{
+	"create table if not exists sample (id varchar(255) not null, name varchar(255) not null, primary key (id))",
+	"create table if not exists user_logs (id integer primary key autoincrement, user_id varchar(100) not null, action varchar(100) not null, created_at datetime, updated_at datetime)",
+}
+
+// tabel yang dikosongkan di awal setiap test pada mode mysql (di dalam transaksi, ikut di-rollback)
+var tables = []string{"sample", "user_roles", "role_permissions", "roles", "uploads", "files", "user_logs", "users"}
+
+var (
+	counter   atomic.Int64
+	mysqlOnce sync.Once
+	mysqlDB   *gorm.DB
+	mysqlErr  error
+)
+
+func isMySQL() bool {
+	return os.Getenv(EnvDriver) == "mysql"
+}
+
+// Open membuka database untuk test. Default-nya SQLite in-memory (pure Go, tanpa server MySQL)
+// yang schema-nya langsung dibuat dari model GORM
+func Open() (*gorm.DB, error) {
+	cfg := config.Default()
+	// output test hanya berisi slow query dan error, test yang memeriksa log query memasang logger sendiri
+	cfg.Database.LogLevel = "warn"
+	if isMySQL() {
+		mysqlOnce.Do(func() {
+			loaded, err := config.Load(nil)
+			if err != nil {
+				mysqlErr = err
+				return
+			}
+			mysqlDB, mysqlErr = database.OpenConnection(loaded.Database)
+		})
+		return mysqlDB, mysqlErr
+	}
+
+	// setiap nama memory database terpisah, shared cache supaya semua koneksi di pool melihat data yang sama
+	dsn := fmt.Sprintf("file:testdb%d?mode=memory&cache=shared&_pragma=foreign_keys(1)", counter.Add(1))
+	db, err := database.Open(sqlite.Open(dsn), cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := ApplySchema(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// ApplySchema membuat semua tabel di SQLite
+func ApplySchema(db *gorm.DB) error {
+	for _, statement := range sqliteTables {
+		if err := db.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return db.AutoMigrate(migration.Models...)
+}
+
+// New memberikan database yang terisolasi untuk satu test: schema baru (SQLite) atau tabel yang dikosongkan (MySQL),
+// fixtures dimuat, lalu semuanya berjalan di dalam transaksi yang di-rollback ketika test selesai
+func New(t testing.TB, fixtures ...Fixture) *gorm.DB {
+	t.Helper()
+
+	tx := open(t).Begin()
+	if tx.Error != nil {
+		t.Fatalf("testdb: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+
+	prepare(t, tx, fixtures)
+	return tx
+}
+
+// Fresh sama seperti New tetapi tanpa transaksi pembungkus, untuk test yang memanggil db.Begin() sendiri
+// (GORM tidak bisa Begin di dalam transaksi). Di mode mysql data test tetap tersimpan sampai test berikutnya
+func Fresh(t testing.TB, fixtures ...Fixture) *gorm.DB {
+	t.Helper()
+
+	db := open(t)
+	prepare(t, db, fixtures)
+	return db
+}
+
+func open(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	db, err := Open()
+	if err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	if !isMySQL() {
+		t.Cleanup(func() {
+			if sqlDB, err := db.DB(); err == nil {
+				sqlDB.Close()
+			}
+		})
+	}
+	return db
+}
+
+func prepare(t testing.TB, db *gorm.DB, fixtures []Fixture) {
+	t.Helper()
+
+	if isMySQL() {
+		for _, table := range tables {
+			if err := db.Exec("delete from " + table).Error; err != nil {
+				t.Fatalf("testdb: %v", err)
+			}
+		}
+	}
+	for _, fixture := range fixtures {
+		if err := fixture(db); err != nil {
+			t.Fatalf("testdb: fixture: %v", err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// File menyimpan metadata file upload, isi file ada di folder upload dengan nama StorageName
+type File struct {
+	ID           string    `gorm:"primary_key;column:id;<-:create"`
+	OriginalName string    `gorm:"column:original_name"` // nama dari client yang sudah dibersihkan, hanya untuk ditampilkan
+	StorageName  string    `gorm:"column:storage_name"`  // nama file di storage, dibuat server (tidak pernah dari client)
+	ContentType  string    `gorm:"column:content_type"`  // hasil deteksi isi file, bukan header dari client
+	Size         int64     `gorm:"column:size"`
+	Checksum     string    `gorm:"column:checksum"` // sha256 hex
+	UploadedBy   string    `gorm:"column:uploaded_by"`
+	CreatedAt    time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (f *File) TableName() string {
+	return "files"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// Role adalah kumpulan permission, satu user bisa memiliki beberapa role lewat tabel user_roles
+type Role struct {
+	ID          string           `gorm:"primary_key;column:id;<-:create"`
+	Description string           `gorm:"column:description"`
+	Permissions []RolePermission `gorm:"foreignKey:RoleId;references:ID"`
+	CreatedAt   time.Time        `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt   time.Time        `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (r *Role) TableName() string {
+	return "roles"
+}
+
+type RolePermission struct {
+	RoleId     string `gorm:"primary_key;column:role_id"`
+	Permission string `gorm:"primary_key;column:permission"` // contoh users:read, lihat security.Permissions
+}
+
+func (r *RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+type UserRole struct {
+	UserId    string    `gorm:"primary_key;column:user_id"`
+	RoleId    string    `gorm:"primary_key;column:role_id;index"`
+	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
+}
+
+func (u *UserRole) TableName() string {
+	return "user_roles"
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Upload adalah sesi upload bertahap (resumable), isi yang sudah diterima ada di file sementara.
+// Setelah Offset sama dengan Length, file dipindah ke storage dan sesi dihapus
+type Upload struct {
+	ID         string    `gorm:"primary_key;column:id;<-:create"`
+	Name       string    `gorm:"column:name"`
+	Length     int64     `gorm:"column:upload_length"`
+	Offset     int64     `gorm:"column:upload_offset"`
+	UploadedBy string    `gorm:"column:uploaded_by"`
+	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+}
+
+func (u *Upload) TableName() string {
+	return "uploads"
+}
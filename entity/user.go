@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 // User => Users (defaul convension dari nama table)
 type User struct {
@@ -9,6 +13,8 @@ type User struct {
 	Name      Name `gorm:"embedded"` // Grouping Field Name
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"` // penulisan ini sudah sesuai dengan conversation dari GORM, jd ini sudah autoCreatedTime ketika data dibuat tambah menambahkan tag
 	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
+	Version   int64 `gorm:"column:version;not null;default:1"` // optimistic locking: naik setiap kali user diubah, update dengan versi lama ditolak
+	DeletedAt gorm.DeletedAt `gorm:"column:deleted_at;index"` // soft delete: Delete hanya mengisi deleted_at, query biasa otomatis mengabaikan user yang sudah dihapus
 	Information string `gorm:"-"` // field permission: tidak ada read/write permission
 }
 
@@ -18,7 +24,7 @@ func (u *User) TableName() string {
 }
 
 type Name struct {
-	FirstName string `gorm:"column:first_name"`
-	MiddleName string `gorm:"column:middle_name"`
-	LastName string `gorm:"column:last_name"`
+	FirstName string `gorm:"column:first_name" json:"first_name"`
+	MiddleName string `gorm:"column:middle_name" json:"middle_name"`
+	LastName string `gorm:"column:last_name" json:"last_name"`
 }
@@ -7,6 +7,7 @@ type UserLogs struct {
 	ID        string `gorm:"primary_key;column:id;autoIncrement"`
 	UserId  string `gorm:"column:user_id"`
 	Action string `gorm:"column:action"`
+	Changes string `gorm:"column:changes"` // JSON daftar field yang berubah (before/after), password selalu disamarkan
 	CreatedAt time.Time `gorm:"column:created_at;autoCreateTime"`
 	UpdatedAt time.Time `gorm:"column:updated_at;autoCreateTime;autoUpdateTime"`
 }
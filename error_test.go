@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"belajar-golang-fiber/exception"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestProblemErrorHandler(t *testing.T) {
+	errorApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	errorApp.Get("/not-found", func(ctx *fiber.Ctx) error {
+		return exception.NotFound("user not found")
+	})
+	errorApp.Get("/conflict", func(ctx *fiber.Ctx) error {
+		return exception.Conflict("username already registered")
+	})
+	errorApp.Get("/forbidden", func(ctx *fiber.Ctx) error {
+		return exception.Forbidden("not allowed")
+	})
+	errorApp.Get("/fiber", func(ctx *fiber.Ctx) error {
+		return fiber.ErrBadRequest
+	})
+	errorApp.Get("/gorm", func(ctx *fiber.Ctx) error {
+		return gorm.ErrRecordNotFound
+	})
+	errorApp.Get("/internal", func(ctx *fiber.Ctx) error {
+		return errors.New("dial tcp 127.0.0.1:3306: connect: connection refused")
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		detail string
+	}{
+		{"/not-found", 404, "user not found"},
+		{"/conflict", 409, "username already registered"},
+		{"/forbidden", 403, "not allowed"},
+		{"/fiber", 400, "Bad Request"},
+		{"/gorm", 404, "record not found"},
+		{"/internal", 500, "internal server error"},
+	}
+
+	for _, test := range tests {
+		request := httptest.NewRequest("GET", test.path, nil)
+		response, err := errorApp.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, test.status, response.StatusCode, test.path)
+		assert.Equal(t, "application/problem+json", response.Header.Get("Content-Type"))
+
+		bytes, err := io.ReadAll(response.Body)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(bytes), "3306")
+
+		problem := exception.Problem{}
+		assert.Nil(t, json.Unmarshal(bytes, &problem))
+		assert.Equal(t, test.status, problem.Status)
+		assert.Equal(t, test.detail, problem.Detail)
+		assert.Equal(t, test.path, problem.Instance)
+		assert.NotEqual(t, "", problem.CorrelationId)
+		assert.Equal(t, problem.CorrelationId, response.Header.Get("X-Correlation-ID"))
+	}
+
+	// tanpa NewRequestId, X-Correlation-ID dari client dicek dengan aturan yang sama dengan request ID
+	for id, valid := range map[string]bool{"client-1": true, "palsu level=error": false, strings.Repeat("a", 129): false} {
+		request := httptest.NewRequest("GET", "/not-found", nil)
+		request.Header.Set("X-Correlation-ID", id)
+		response, err := errorApp.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, valid, response.Header.Get("X-Correlation-ID") == id, id)
+		assert.NotEqual(t, "", response.Header.Get("X-Correlation-ID"), id)
+	}
+}
@@ -0,0 +1,115 @@
+package exception
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindValidation
+	KindConflict
+	KindUnauthorized
+	KindForbidden
+	KindTooLarge
+	KindUnsupportedMediaType
+	KindPreconditionFailed
+	KindPreconditionRequired
+)
+
+// Error adalah error aplikasi yang sudah diketahui jenisnya,
+// ErrorHandler akan mengubahnya menjadi status code yang sesuai
+type Error struct {
+	Kind    Kind
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindNotFound:
+		return fiber.StatusNotFound
+	case KindValidation:
+		return fiber.StatusUnprocessableEntity
+	case KindConflict:
+		return fiber.StatusConflict
+	case KindUnauthorized:
+		return fiber.StatusUnauthorized
+	case KindForbidden:
+		return fiber.StatusForbidden
+	case KindTooLarge:
+		return fiber.StatusRequestEntityTooLarge
+	case KindUnsupportedMediaType:
+		return fiber.StatusUnsupportedMediaType
+	case KindPreconditionFailed:
+		return fiber.StatusPreconditionFailed
+	case KindPreconditionRequired:
+		return fiber.StatusPreconditionRequired
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Kind: KindNotFound, Message: message}
+}
+
+func Validation(message string) *Error {
+	return &Error{Kind: KindValidation, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Kind: KindConflict, Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Kind: KindUnauthorized, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Kind: KindForbidden, Message: message}
+}
+
+func TooLarge(message string) *Error {
+	return &Error{Kind: KindTooLarge, Message: message}
+}
+
+func UnsupportedMediaType(message string) *Error {
+	return &Error{Kind: KindUnsupportedMediaType, Message: message}
+}
+
+// PreconditionFailed dipakai jika If-Match tidak sesuai dengan versi data saat ini
+func PreconditionFailed(message string) *Error {
+	return &Error{Kind: KindPreconditionFailed, Message: message}
+}
+
+// PreconditionRequired dipakai jika request update tidak membawa If-Match
+func PreconditionRequired(message string) *Error {
+	return &Error{Kind: KindPreconditionRequired, Message: message}
+}
+
+// Internal membungkus error yang tidak boleh terlihat oleh client, detailnya hanya masuk ke log
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Message: "internal server error", Err: err}
+}
+
+// Is memudahkan pengecekan jenis error, contoh exception.Is(err, exception.KindNotFound)
+func Is(err error, kind Kind) bool {
+	var appError *Error
+	return errors.As(err, &appError) && appError.Kind == kind
+}
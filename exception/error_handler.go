@@ -0,0 +1,84 @@
+package exception
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"belajar-golang-fiber/logging"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// HeaderCorrelationId dikirim di response error dan juga dibaca middleware.NewRequestId sebagai request ID
+const HeaderCorrelationId = "X-Correlation-ID"
+
+// Problem adalah body response error sesuai RFC 7807
+type Problem struct {
+	Type          string                  `json:"type"`
+	Title         string                  `json:"title"`
+	Status        int                     `json:"status"`
+	Detail        string                  `json:"detail,omitempty"`
+	Instance      string                  `json:"instance,omitempty"`
+	CorrelationId string                  `json:"correlation_id"`
+	Errors        []validation.FieldError `json:"errors,omitempty"`
+}
+
+// ErrorHandler adalah fiber.Config.ErrorHandler untuk aplikasi
+func ErrorHandler(ctx *fiber.Ctx, err error) error {
+	problem := Problem{
+		Type:          "about:blank",
+		Instance:      ctx.OriginalURL(),
+		CorrelationId: correlationId(ctx),
+	}
+
+	var appError *Error
+	var validationError *validation.Error
+	var fiberError *fiber.Error
+	switch {
+	case errors.As(err, &validationError):
+		problem.Status = fiber.StatusUnprocessableEntity
+		problem.Detail = "request validation failed"
+		problem.Errors = validationError.Fields
+	case errors.As(err, &appError):
+		problem.Status = appError.Status()
+		problem.Detail = appError.Message
+	case errors.As(err, &fiberError):
+		problem.Status = fiberError.Code
+		problem.Detail = fiberError.Message
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		problem.Status = fiber.StatusNotFound
+		problem.Detail = "record not found"
+	default:
+		problem.Status = fiber.StatusInternalServerError
+	}
+
+	// detail error internal (query, koneksi database, dll) tidak dikirim ke client
+	if problem.Status >= fiber.StatusInternalServerError {
+		problem.Detail = "internal server error"
+		slog.ErrorContext(ctx.UserContext(), "internal server error",
+			slog.String("method", ctx.Method()), slog.String("url", ctx.OriginalURL()), slog.Any("error", err))
+	}
+	problem.Title = http.StatusText(problem.Status)
+
+	ctx.Set(HeaderCorrelationId, problem.CorrelationId)
+	ctx.Set(fiber.HeaderXRequestID, problem.CorrelationId)
+	return ctx.Status(problem.Status).JSON(problem, MIMEApplicationProblemJSON)
+}
+
+// correlationId sama dengan request ID dari middleware NewRequestId, tanpa middleware tersebut
+// dipakai X-Correlation-ID dari client (dengan aturan yang sama) atau UUID baru
+func correlationId(ctx *fiber.Ctx) string {
+	if id := logging.RequestId(ctx.UserContext()); id != "" {
+		return id
+	}
+	if id := ctx.Get(HeaderCorrelationId); logging.ValidRequestId(id) {
+		return id
+	}
+	return uuid.NewString()
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database/testdb"
 	entity "belajar-golang-fiber/entity"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/storage"
 	"bytes"
 	_ "embed"
 	"encoding/json"
@@ -130,14 +137,29 @@ func TestFormRequest(t *testing.T) {
 //go:embed source/contoh.txt
 var contohFile []byte
 func TestFormUpload(t *testing.T) {
+	// nama file dari client tidak dipakai sebagai path, file disimpan lewat FileService
+	local, err := storage.NewLocal(t.TempDir())
+	assert.Nil(t, err)
+	fileService := service.NewFileService(repository.NewMemoryStore(), local, security.NewURLSigner(""), config.UploadConfig{
+		MaxSize:      1024 * 1024,
+		MaxFiles:     1,
+		AllowedTypes: "text/plain",
+	}, config.Default().Storage)
+
 	app.Post("/upload", func(ctx *fiber.Ctx) error {
 		file, err := ctx.FormFile("file")
 		
 		if err!= nil {
             return err
         }
-		
-		err = ctx.SaveFile(file, "./target/"+file.Filename)
+
+		content, err := file.Open()
+		if err != nil {
+			return err
+		}
+		defer content.Close()
+
+		_, err = fileService.Upload(ctx.UserContext(), "", model.UploadFile{Name: file.Filename, Reader: content})
 		if err!= nil {
 			return err
 		}
@@ -285,8 +307,17 @@ func TestResponseJSON(t *testing.T) {
 }
 
 func TestDownloadFile(t *testing.T) {
+	// file dibaca lewat storage, bukan langsung dari path disk
+	source, err := storage.NewLocal("./source")
+	assert.Nil(t, err)
+
 	app.Get("/download", func(ctx *fiber.Ctx) error {
-		return ctx.Download("./source/contoh.txt", "contoh.txt")
+		content, err := source.Open(ctx.UserContext(), "contoh.txt")
+		if err != nil {
+			return err
+		}
+		ctx.Attachment("contoh.txt")
+		return ctx.SendStream(content)
     })
 
 	request := httptest.NewRequest("GET", "/download", nil)
@@ -388,6 +419,7 @@ func TestClient(t *testing.T) {
 }
 
 func TestAutoIncrement(t *testing.T) {
+	db := testdb.New(t)
 	for i := 0; i < 10; i++ {
 		userLog := entity.UserLogs{
 			UserId: "1",
@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+// PNG 1x1 pixel, cukup untuk deteksi image/png
+var pngFile = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+func newFileApp(t *testing.T, store repository.Store, maxSize int) (*fiber.App, string) {
+	dir := t.TempDir()
+	local, err := storage.NewLocal(dir)
+	assert.Nil(t, err)
+	return newFileAppWithStorage(store, local, maxSize), dir
+}
+
+func newFileAppWithStorage(store repository.Store, fileStorage storage.Storage, maxSize int) *fiber.App {
+	uploadConfig := config.UploadConfig{
+		MaxSize:      maxSize,
+		MaxFiles:     2,
+		AllowedTypes: "image/png,text/plain",
+		Expiration:   time.Hour,
+	}
+	signer := security.NewURLSigner("rahasia")
+	fileService := service.NewFileService(store, fileStorage, signer, uploadConfig, config.Default().Storage)
+
+	fileApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler, StreamRequestBody: true})
+	// header X-User menggantikan session login supaya test bisa memakai beberapa user
+	api := fileApp.Group("/api", func(ctx *fiber.Ctx) error {
+		ctx.Locals(middleware.SessionUserKey, ctx.Get("X-User", "uploader-1"))
+		if permissions := ctx.Get("X-Permissions"); permissions != "" {
+			ctx.Locals(middleware.PermissionsKey, security.PermissionSet(strings.Split(permissions, ",")))
+		}
+		return ctx.Next()
+	})
+	controller.NewFileController(fileService).Route(api)
+	uploadService := service.NewUploadService(store, fileService, uploadConfig)
+	controller.NewUploadController(uploadService, maxSize).Route(api)
+	return fileApp
+}
+
+func multipartBody(t *testing.T, files map[string][]byte) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for name, content := range files {
+		part, err := writer.CreateFormFile("files", name)
+		assert.Nil(t, err)
+		part.Write(content)
+	}
+	assert.Nil(t, writer.Close())
+	return body, writer.FormDataContentType()
+}
+
+func storedFiles(t *testing.T, dir string) int {
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	return len(entries)
+}
+
+func TestFileUploadDownload(t *testing.T) {
+	db := testdb.New(t)
+	fileApp, dir := newFileApp(t, repository.NewGormStore(db), 1024)
+
+	body, contentType := multipartBody(t, map[string][]byte{
+		"../../etc/passwd.txt": []byte("isi file teks"),
+		"gambar.png":           pngFile,
+	})
+	request := httptest.NewRequest("POST", "/api/files", body)
+	request.Header.Set("Content-Type", contentType)
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	filesResponse := new(model.WebResponse[[]model.FileResponse])
+	assert.Nil(t, json.Unmarshal(bytes, filesResponse))
+	assert.Equal(t, 2, len(filesResponse.Data))
+	assert.Equal(t, 2, storedFiles(t, dir))
+
+	var text model.FileResponse
+	for _, file := range filesResponse.Data {
+		if file.ContentType == "image/png" {
+			assert.Equal(t, "gambar.png", file.Name)
+			continue
+		}
+		text = file
+	}
+	checksum := sha256.Sum256([]byte("isi file teks"))
+	assert.Equal(t, "passwd.txt", text.Name)
+	assert.Equal(t, "text/plain; charset=utf-8", text.ContentType)
+	assert.Equal(t, int64(13), text.Size)
+	assert.Equal(t, hex.EncodeToString(checksum[:]), text.Checksum)
+	assert.Equal(t, "uploader-1", text.UploadedBy)
+
+	request = httptest.NewRequest("GET", "/api/files/"+text.ID, nil)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/files/"+text.ID+"/content", nil)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "text/plain; charset=utf-8", response.Header.Get("Content-Type"))
+	assert.Equal(t, `attachment; filename=passwd.txt`, response.Header.Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", response.Header.Get("X-Content-Type-Options"))
+
+	content, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "isi file teks", string(content))
+
+	request = httptest.NewRequest("GET", "/api/files/tidak-ada/content", nil)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+}
+
+func TestFileUploadStream(t *testing.T) {
+	fileApp, dir := newFileApp(t, repository.NewMemoryStore(), 1024)
+
+	request := httptest.NewRequest("POST", "/api/files?name=gambar.png", bytes.NewReader(pngFile))
+	request.Header.Set("Content-Type", "application/octet-stream")
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	filesResponse := new(model.WebResponse[[]model.FileResponse])
+	assert.Nil(t, json.Unmarshal(bytes, filesResponse))
+	assert.Equal(t, "gambar.png", filesResponse.Data[0].Name)
+	assert.Equal(t, "image/png", filesResponse.Data[0].ContentType)
+
+	entries, err := os.ReadDir(dir)
+	assert.Nil(t, err)
+	assert.Equal(t, filesResponse.Data[0].ID+".png", entries[0].Name())
+}
+
+func uploadPng(t *testing.T, fileApp *fiber.App) string {
+	request := httptest.NewRequest("POST", "/api/files?name=gambar.png", bytes.NewReader(pngFile))
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	filesResponse := new(model.WebResponse[[]model.FileResponse])
+	assert.Nil(t, json.Unmarshal(bytes, filesResponse))
+	return filesResponse.Data[0].ID
+}
+
+func TestFileOwnerAccess(t *testing.T) {
+	fileApp, _ := newFileApp(t, repository.NewMemoryStore(), 1024)
+	id := uploadPng(t, fileApp)
+
+	for _, path := range []string{"", "/content", "/url"} {
+		request := httptest.NewRequest("GET", "/api/files/"+id+path, nil)
+		response, err := fileApp.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, response.StatusCode, path)
+
+		// user lain tidak boleh membaca file yang bukan miliknya
+		request = httptest.NewRequest("GET", "/api/files/"+id+path, nil)
+		request.Header.Set("X-User", "uploader-2")
+		request.Header.Set("X-Permissions", security.PermFilesRead)
+		response, err = fileApp.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, 403, response.StatusCode, path)
+
+		request = httptest.NewRequest("GET", "/api/files/"+id+path, nil)
+		request.Header.Set("X-User", "uploader-2")
+		request.Header.Set("X-Permissions", "files:*")
+		response, err = fileApp.Test(request)
+		assert.Nil(t, err)
+		assert.Equal(t, 200, response.StatusCode, path)
+	}
+}
+
+func TestFileUploadRejected(t *testing.T) {
+	fileApp, dir := newFileApp(t, repository.NewMemoryStore(), 16)
+
+	upload := func(files map[string][]byte) int {
+		body, contentType := multipartBody(t, files)
+		request := httptest.NewRequest("POST", "/api/files", body)
+		request.Header.Set("Content-Type", contentType)
+		response, err := fileApp.Test(request)
+		assert.Nil(t, err)
+		return response.StatusCode
+	}
+
+	assert.Equal(t, 413, upload(map[string][]byte{"besar.txt": []byte("lebih dari enam belas byte")}))
+	assert.Equal(t, 415, upload(map[string][]byte{"index.txt": []byte("<html><body>")}))
+	assert.Equal(t, 422, upload(map[string][]byte{"kosong.txt": {}}))
+	assert.Equal(t, 422, upload(map[string][]byte{"a.txt": []byte("a"), "b.txt": []byte("b"), "c.txt": []byte("c")}))
+	// satu file gagal, file lain dalam request yang sama ikut dibatalkan
+	assert.Equal(t, 413, upload(map[string][]byte{"a.txt": []byte("a"), "b.txt": []byte("lebih dari enam belas byte")}))
+	assert.Equal(t, 0, storedFiles(t, dir))
+}
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := map[string]string{
+		"contoh.txt":            "contoh.txt",
+		"../../etc/passwd":      "passwd",
+		`..\..\windows\win.ini`: "win.ini",
+		"..":                    "file",
+		"":                      "file",
+		".htaccess":             "htaccess",
+		"la\x00po\nran?.pdf":    "laporan.pdf",
+		`"quote".txt`:           "quote.txt",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, service.SanitizeFilename(input), input)
+	}
+}
+
+func TestFileDownloadRange(t *testing.T) {
+	fileApp, _ := newFileApp(t, repository.NewMemoryStore(), 1024)
+	id := uploadPng(t, fileApp)
+	size := len(pngFile)
+
+	download := func(headers map[string]string) *http.Response {
+		request := httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+		for key, value := range headers {
+			request.Header.Set(key, value)
+		}
+		response, err := fileApp.Test(request)
+		assert.Nil(t, err)
+		return response
+	}
+
+	response := download(map[string]string{"Range": "bytes=1-3"})
+	assert.Equal(t, 206, response.StatusCode)
+	assert.Equal(t, fmt.Sprintf("bytes 1-3/%d", size), response.Header.Get("Content-Range"))
+	content, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, "PNG", string(content))
+
+	response = download(map[string]string{"Range": "bytes=-4"})
+	assert.Equal(t, 206, response.StatusCode)
+	content, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, pngFile[size-4:], content)
+
+	response = download(map[string]string{"Range": "bytes=0-0,8-"})
+	assert.Equal(t, 206, response.StatusCode)
+	mediaType, params, err := mime.ParseMediaType(response.Header.Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/byteranges", mediaType)
+
+	reader := multipart.NewReader(response.Body, params["boundary"])
+	part, err := reader.NextPart()
+	assert.Nil(t, err)
+	assert.Equal(t, fmt.Sprintf("bytes 0-0/%d", size), part.Header.Get("Content-Range"))
+	assert.Equal(t, "image/png", part.Header.Get("Content-Type"))
+	content, _ = io.ReadAll(part)
+	assert.Equal(t, pngFile[:1], content)
+	part, err = reader.NextPart()
+	assert.Nil(t, err)
+	content, _ = io.ReadAll(part)
+	assert.Equal(t, pngFile[8:], content)
+	_, err = reader.NextPart()
+	assert.Equal(t, io.EOF, err)
+
+	response = download(map[string]string{"Range": "bytes=1000-"})
+	assert.Equal(t, 416, response.StatusCode)
+	assert.Equal(t, fmt.Sprintf("bytes */%d", size), response.Header.Get("Content-Range"))
+
+	// header tidak valid diabaikan, file dikirim utuh
+	response = download(map[string]string{"Range": "bytes=5-1"})
+	assert.Equal(t, 200, response.StatusCode)
+
+	// If-Range tidak cocok (file sudah berubah menurut client) => kirim utuh
+	response = download(map[string]string{"Range": "bytes=1-3", "If-Range": `"lama"`})
+	assert.Equal(t, 200, response.StatusCode)
+	etag := response.Header.Get("ETag")
+	response = download(map[string]string{"Range": "bytes=1-3", "If-Range": etag})
+	assert.Equal(t, 206, response.StatusCode)
+}
+
+// abortStorage mengembalikan isi range berupa byte nol dan mencatat setiap reader yang ditutup
+type abortStorage struct {
+	storage.Storage
+	closed chan struct{}
+}
+
+type abortSection struct {
+	io.Reader
+	closed chan struct{}
+}
+
+func (s abortSection) Close() error {
+	s.closed <- struct{}{}
+	return nil
+}
+
+func (s abortStorage) OpenRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error) {
+	return abortSection{Reader: io.LimitReader(zeroReader{}, length), closed: s.closed}, nil
+}
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// client yang memutus koneksi di tengah multipart/byteranges tidak boleh membuat goroutine penulis menggantung
+func TestFileDownloadRangesAborted(t *testing.T) {
+	store := repository.NewMemoryStore()
+	local, err := storage.NewLocal(t.TempDir())
+	assert.Nil(t, err)
+	fileStorage := abortStorage{Storage: local, closed: make(chan struct{}, 2)}
+	size := int64(64 << 20)
+	assert.Nil(t, store.Files().Create(context.Background(), &entity.File{
+		ID: "besar", StorageName: "besar", ContentType: "text/plain", Size: size, Checksum: "abc", UploadedBy: "uploader-1",
+	}))
+
+	fileApp := newFileAppWithStorage(store, fileStorage, 1024)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go fileApp.Listener(listener)
+	defer fileApp.Shutdown()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	assert.Nil(t, err)
+	fmt.Fprintf(conn, "GET /api/files/besar/content HTTP/1.1\r\nHost: test\r\nRange: bytes=0-%d,%d-\r\n\r\n", size/2-1, size/2)
+	_, err = io.ReadFull(conn, make([]byte, 4096))
+	assert.Nil(t, err)
+	assert.Nil(t, conn.Close())
+
+	select {
+	case <-fileStorage.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("range section was not closed after the client disconnected")
+	}
+	// range kedua tidak pernah dibuka
+	select {
+	case <-fileStorage.closed:
+		t.Fatal("second range was opened after the client disconnected")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFileDownloadConditional(t *testing.T) {
+	fileApp, _ := newFileApp(t, repository.NewMemoryStore(), 1024)
+	id := uploadPng(t, fileApp)
+
+	request := httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "bytes", response.Header.Get("Accept-Ranges"))
+	etag := response.Header.Get("ETag")
+	lastModified := response.Header.Get("Last-Modified")
+	assert.NotEqual(t, "", etag)
+	assert.NotEqual(t, "", lastModified)
+
+	request = httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+	request.Header.Set("If-None-Match", `"lain", `+etag)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 304, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+	request.Header.Set("If-None-Match", `"lain"`)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+	request.Header.Set("If-Modified-Since", lastModified)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 304, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/files/"+id+"/content", nil)
+	request.Header.Set("If-Modified-Since", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+}
@@ -0,0 +1,154 @@
+package fixture
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"belajar-golang-fiber/migration"
+	"belajar-golang-fiber/security"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// Loader memuat data dari file YAML/JSON ke database. Satu file berisi satu tabel,
+// nama tabel diambil dari nama file (users.yaml => users) dan isinya adalah daftar row:
+//
+//   - id: "1"
+//     password: rahasia
+//     name:               # field embedded entity.Name boleh ditulis bersarang
+//     first_name: Bagus
+//     last_name: Wicaksono # atau langsung dengan nama kolomnya
+type Loader struct {
+	DB *gorm.DB
+	// Hasher jika diisi dipakai untuk hash kolom password tabel users (untuk seed database dev)
+	Hasher *security.PasswordHasher
+}
+
+func New(db *gorm.DB) *Loader {
+	return &Loader{DB: db}
+}
+
+// Files menghasilkan fungsi fixture yang bisa dipakai langsung dengan testdb.New
+func Files(paths ...string) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		return New(tx).Reset(paths...)
+	}
+}
+
+// Load menambahkan semua row dari file sesuai urutan file
+func (l *Loader) Load(paths ...string) error {
+	for _, path := range paths {
+		table, rows, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		if err := l.insert(table, rows); err != nil {
+			return fmt.Errorf("fixture: %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// Reset mengosongkan tabel-tabel dari file (urutan terbalik) lalu memuat ulang datanya
+func (l *Loader) Reset(paths ...string) error {
+	for i := len(paths) - 1; i >= 0; i-- {
+		if err := l.DB.Exec("delete from " + tableName(paths[i])).Error; err != nil {
+			return fmt.Errorf("fixture: %s: %w", paths[i], err)
+		}
+	}
+	return l.Load(paths...)
+}
+
+func tableName(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+func readFile(path string) (string, []map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("fixture: %w", err)
+	}
+
+	// YAML adalah superset JSON, jadi file .json juga bisa dibaca dengan decoder yang sama
+	var rows []map[string]interface{}
+	if err := yaml.Unmarshal(content, &rows); err != nil {
+		return "", nil, fmt.Errorf("fixture: %s: %w", path, err)
+	}
+	return tableName(path), rows, nil
+}
+
+func (l *Loader) insert(table string, rows []map[string]interface{}) error {
+	columns, hasModel, err := l.columns(table)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i, raw := range rows {
+		row := flatten(raw)
+		for column := range row {
+			if hasModel && !columns[column] {
+				return fmt.Errorf("row %d: unknown column %s.%s", i+1, table, column)
+			}
+		}
+		for _, column := range []string{"created_at", "updated_at"} {
+			if _, ok := row[column]; !ok && columns[column] {
+				row[column] = now
+			}
+		}
+		if table == "users" && l.Hasher != nil {
+			if password, ok := row["password"].(string); ok && !security.IsHashed(password) {
+				if row["password"], err = l.Hasher.Hash(password); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := l.DB.Table(table).Create(row).Error; err != nil {
+			return fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// columns mengambil daftar kolom dari model GORM untuk tabel tersebut (termasuk kolom embedded),
+// tabel tanpa model (contoh sample) tidak dicek kolomnya
+func (l *Loader) columns(table string) (map[string]bool, bool, error) {
+	for _, model := range migration.Models {
+		statement := &gorm.Statement{DB: l.DB}
+		if err := statement.Parse(model); err != nil {
+			return nil, false, err
+		}
+		if statement.Schema.Table != table {
+			continue
+		}
+
+		columns := map[string]bool{}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName != "" {
+				columns[field.DBName] = true
+			}
+		}
+		return columns, true, nil
+	}
+	return map[string]bool{}, false, nil
+}
+
+// flatten mengubah field bersarang (contoh name: {first_name: Bagus}) menjadi kolom biasa
+func flatten(row map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range row {
+		if nested, ok := value.(map[string]interface{}); ok {
+			for column, nestedValue := range flatten(nested) {
+				result[column] = nestedValue
+			}
+			continue
+		}
+		result[key] = value
+	}
+	return result
+}
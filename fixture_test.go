@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"belajar-golang-fiber/audit"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/fixture"
+	"belajar-golang-fiber/security"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtureLoad(t *testing.T) {
+	db := testdb.New(t, fixture.Files("testdata/fixtures/users.yaml", "testdata/fixtures/user_logs.json"))
+
+	var user entity.User
+	assert.Nil(t, db.Take(&user, "id = ?", "1").Error)
+	assert.Equal(t, "Bagus", user.Name.FirstName)
+	assert.Equal(t, "Testing", user.Name.MiddleName)
+	assert.Equal(t, "Wicaksono", user.Name.LastName)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	var logs []entity.UserLogs
+	assert.Nil(t, db.Order("id").Find(&logs, "user_id = ?", "1").Error)
+	assert.Equal(t, 2, len(logs))
+	assert.Equal(t, audit.ActionUpdate, logs[1].Action)
+
+	changes := audit.Changes(&logs[1])
+	assert.Equal(t, "last_name", changes[0].Field)
+}
+
+func TestFixtureReset(t *testing.T) {
+	db := testdb.New(t)
+	assert.Nil(t, db.Create(&entity.User{ID: "99", Password: "rahasia", Name: entity.Name{FirstName: "Lama"}}).Error)
+
+	loader := fixture.New(db)
+	assert.Nil(t, loader.Reset("testdata/fixtures/users.yaml"))
+
+	var count int64
+	assert.Nil(t, db.Model(&entity.User{}).Count(&count).Error)
+	assert.Equal(t, int64(14), count)
+	assert.NotNil(t, db.Take(&entity.User{}, "id = ?", "99").Error)
+}
+
+func TestFixtureHashPassword(t *testing.T) {
+	db := testdb.New(t)
+
+	loader := fixture.New(db)
+	loader.Hasher = security.NewPasswordHasher(security.DefaultPasswordCost)
+	assert.Nil(t, loader.Load("seed/users.yaml"))
+
+	var user entity.User
+	assert.Nil(t, db.Take(&user, "id = ?", "admin").Error)
+	assert.True(t, security.IsHashed(user.Password))
+
+	ok, _ := loader.Hasher.Verify(user.Password, "admin12345")
+	assert.True(t, ok)
+}
+
+func TestFixtureUnknownColumn(t *testing.T) {
+	db := testdb.New(t)
+
+	file := t.TempDir() + "/users.yaml"
+	assert.Nil(t, os.WriteFile(file, []byte("- id: \"1\"\n  nama: Bagus\n"), 0644))
+
+	err := fixture.New(db).Load(file)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown column users.nama")
+}
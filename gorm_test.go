@@ -4,19 +4,18 @@ import (
 	"strconv"
 	"testing"
 
+	"belajar-golang-fiber/database/testdb"
 	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/fixture"
 
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
+// OpenConnection membuka database test: default SQLite in-memory sehingga test bisa jalan tanpa MySQL,
+// set APP_TEST_DATABASE=mysql untuk memakai MySQL dari config
 func OpenConnection() *gorm.DB {
-	dialect := mysql.Open("root:@tcp(127.0.0.1:3306)/belajar_golang_gorm?charset=utf8mb4&parseTime=True&loc=Local")
-	db, err := gorm.Open(dialect, &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), //mengaktifkan logger pada GORM (semua perintah sql akan kelihatan)
-	})
+	db, err := testdb.Open()
 	if err != nil {
 		panic(err)
 	}
@@ -26,11 +25,19 @@ func OpenConnection() *gorm.DB {
 
 var db = OpenConnection()
 
+// Data awal untuk test query, sama dengan kondisi setelah test insert dijalankan berurutan:
+// user 1 (Bagus Testing Wicaksono) dan user 2 - 14 yang first_name-nya mengandung "User"
+var (
+	usersFixture   = fixture.Files("testdata/fixtures/users.yaml")
+	samplesFixture = fixture.Files("testdata/fixtures/sample.yaml")
+)
+
 func TestOpenConnection(t *testing.T) {
 	assert.NotNil(t, db)
 }
 
 func TestExecuteSQL(t *testing.T) {
+	db := testdb.New(t)
 	err := db.Exec("insert into sample(id, name) values (?, ?)", "1", "Bagus").Error
 	assert.Nil(t, err)
 
@@ -50,6 +57,7 @@ type Sample struct {
 }
 
 func TestRawSql(t *testing.T) {
+	db := testdb.New(t, samplesFixture)
 	var sample Sample
 	err := db.Raw("select id, name from sample where id = ?", "1").Scan(&sample).Error
 	assert.Nil(t, err)
@@ -63,6 +71,7 @@ func TestRawSql(t *testing.T) {
 }
 
 func TestSqlRow(t *testing.T) {
+	db := testdb.New(t, samplesFixture)
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close() // Agar tidak memory lick
@@ -84,6 +93,7 @@ func TestSqlRow(t *testing.T) {
 }
 
 func TestScanRow(t *testing.T) {
+	db := testdb.New(t, samplesFixture)
 	rows, err := db.Raw("select id, name from sample").Rows()
 	assert.Nil(t, err)
 	defer rows.Close() // Agar tidak memory lick
@@ -97,6 +107,7 @@ func TestScanRow(t *testing.T) {
 }
 
 func TestCreateUser(t *testing.T) {
+	db := testdb.New(t)
 	// import Struct User from folder Entity
 	user := entity.User{ID: "1", Password: "rahasia", Name: entity.Name{
 		FirstName: "Bagus",
@@ -111,6 +122,7 @@ func TestCreateUser(t *testing.T) {
 
 // Memasukkan data lebih dari 1
 func TestBatchInsert(t *testing.T) {
+	db := testdb.New(t)
 	var users []entity.User
 	for i :=2; i < 10; i++ {
 		users = append(users, entity.User{
@@ -128,6 +140,7 @@ func TestBatchInsert(t *testing.T) {
 }
 
 func TestTransactionSuccess(t *testing.T) {
+	db := testdb.New(t)
 	err := db.Transaction(func (tx *gorm.DB) error {
 		err := tx.Create(&entity.User{ID: "10",Password: "rahasia",Name: entity.Name{FirstName: "User 10",}}).Error
 		if err!= nil {
@@ -152,6 +165,7 @@ func TestTransactionSuccess(t *testing.T) {
 
 
 func TestTransactionError(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	err := db.Transaction(func(tx *gorm.DB) error {
 		err := tx.Create(&entity.User{ID: "13",Password: "rahasia",Name: entity.Name{FirstName: "User 13",}}).Error
 		if err!= nil {
@@ -171,6 +185,7 @@ func TestTransactionError(t *testing.T) {
 
 // DB Transaction manual tidak direkomendasikan
 func TestManualTransactionSuccess(t *testing.T) {
+	db := testdb.Fresh(t)
 	tx := db.Begin()
 	defer tx.Rollback()
 
@@ -186,6 +201,7 @@ func TestManualTransactionSuccess(t *testing.T) {
 }
 
 func TestManualTransactionError(t *testing.T) {
+	db := testdb.Fresh(t)
 	tx := db.Begin()
     defer tx.Rollback()
 
@@ -201,6 +217,7 @@ func TestManualTransactionError(t *testing.T) {
 }
 
 func TestQuerrySingleObject(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	user := entity.User{}
 	err := db.First(&user).Error // mengambil 1 data pertama berdasarkan id
 	assert.Nil(t, err)
@@ -214,6 +231,7 @@ func TestQuerrySingleObject(t *testing.T) {
 }
 
 func TestQuerryInlineCondition(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	user := entity.User{}
 	err := db.Take(&user, "id = ?", "5").Error
 	assert.Nil(t, err)
@@ -222,6 +240,7 @@ func TestQuerryInlineCondition(t *testing.T) {
 }
 
 func TestQueryAllObjects(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
 	err := db.Find(&users, "id in ?", []string{"1","2","3","4"}).Error
 	assert.Nil(t, err)
@@ -229,6 +248,7 @@ func TestQueryAllObjects(t *testing.T) {
 }
 
 func TestQueryCondition(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
 	err := db.Where("first_name like ?", "%User%").Where("password = ?", "rahasia").Find(&users).Error
 	assert.Nil(t, err)
@@ -236,6 +256,7 @@ func TestQueryCondition(t *testing.T) {
 }
 
 func TestQueryOrOperator(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
     err := db.Where("first_name like?", "%User%").Or("password =?", "rahasia").Find(&users).Error
     assert.Nil(t, err)
@@ -243,6 +264,7 @@ func TestQueryOrOperator(t *testing.T) {
 }
 
 func TestQueryNotOperator(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
     err := db.Not("first_name like ?", "%User%").Where("password = ?", "rahasia").Find(&users).Error
     assert.Nil(t, err)
@@ -250,6 +272,7 @@ func TestQueryNotOperator(t *testing.T) {
 }
 
 func TestSelectFields(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
 	err := db.Select("id", "first_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -263,6 +286,7 @@ func TestSelectFields(t *testing.T) {
 }
 
 func TestStructCondition(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	userCondtion := entity.User {
 		Name: entity.Name{
 			FirstName: "User5",
@@ -279,6 +303,7 @@ func TestStructCondition(t *testing.T) {
 
 
 func TestMapCondition(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	mapCondtion := map[string]interface{}{
 		"middle_name" : "",
 		"last_name" : "",
@@ -291,6 +316,7 @@ func TestMapCondition(t *testing.T) {
 }
 
 func TestOrderLimitOffset(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []entity.User
     err := db.Order("id asc, first_name desc").Limit(5).Offset(5).Find(&users).Error
     assert.Nil(t, err)
@@ -304,6 +330,7 @@ type UserResponse struct {
 }
 
 func TestQueryNonModel(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	var users []UserResponse
 	err :=db.Model(&entity.User{}).Select("id", "first_name", "last_name").Find(&users).Error
 	assert.Nil(t, err)
@@ -311,6 +338,7 @@ func TestQueryNonModel(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	user := entity.User{}
 	err := db.Take(&user, "id = ?", "1").Error
 	assert.Nil(t, err)
@@ -325,6 +353,7 @@ func TestUpdate(t *testing.T) {
 }
 
 func TestUpdateSelectedColumns(t *testing.T) {
+	db := testdb.New(t, usersFixture)
 	err := db.Model(&entity.User{}).Where("id = ?", "1").Updates(map[string]interface{} {
 		"middle_name": "",
 		"last_name": "Morro",
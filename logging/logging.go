@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+
+	"belajar-golang-fiber/config"
+)
+
+// Redacted menggantikan nilai sensitif di log: header di log.redact_headers dan parameter query
+// untuk kolom di database.redact_columns
+const Redacted = "[REDACTED]"
+
+// New membuat logger slog sesuai konfigurasi, format json menghasilkan satu objek JSON per baris.
+// Request ID dan trace di context ikut dicatat (request_id, trace_id, span_id). level diisi dari cfg.Level dan bisa diubah
+// saat aplikasi berjalan
+func New(cfg config.LogConfig, w io.Writer, level *slog.LevelVar) (*slog.Logger, error) {
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return nil, err
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	if cfg.Format == "text" {
+		return slog.New(contextHandler{slog.NewTextHandler(w, options)}), nil
+	}
+	return slog.New(contextHandler{slog.NewJSONHandler(w, options)}), nil
+}
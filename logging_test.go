@@ -0,0 +1,298 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/logging"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func newLoggedApp(t *testing.T, cfg config.LogConfig) (*fiber.App, *bytes.Buffer) {
+	output := &bytes.Buffer{}
+	logger, err := logging.New(cfg, output, new(slog.LevelVar))
+	assert.Nil(t, err)
+
+	loggedApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	loggedApp.Use(middleware.NewRequestId(), middleware.NewRequestLogger(logger, cfg))
+	api := loggedApp.Group("/api")
+	api.Get("/users/:userId/orders/:orderId", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("order " + ctx.Params("orderId"))
+	})
+	api.Get("/error", func(ctx *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+	return loggedApp, output
+}
+
+func readLogRecords(t *testing.T, output *bytes.Buffer) []map[string]any {
+	var records []map[string]any
+	scanner := bufio.NewScanner(output)
+	for scanner.Scan() {
+		record := map[string]any{}
+		assert.Nil(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestRequestLogger(t *testing.T) {
+	cfg := config.Default().Log
+	cfg.Headers = true
+	loggedApp, output := newLoggedApp(t, cfg)
+
+	request := httptest.NewRequest("GET", "/api/users/Bagus/orders/10", nil)
+	request.Header.Set("Authorization", "Bearer rahasia")
+	request.Header.Set("Cookie", "session_id=rahasia")
+	request.Header.Set("X-Request-ID", "request-1")
+	request.Header.Set("Accept", "text/plain")
+	response, err := loggedApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	response, err = loggedApp.Test(httptest.NewRequest("GET", "/api/error", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 500, response.StatusCode)
+
+	response, err = loggedApp.Test(httptest.NewRequest("GET", "/api/tidak-ada/123", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+
+	assert.NotContains(t, output.String(), "rahasia")
+	records := readLogRecords(t, output)
+	assert.Equal(t, 3, len(records))
+
+	assert.Equal(t, "INFO", records[0]["level"])
+	assert.Equal(t, "request", records[0]["msg"])
+	assert.Equal(t, "GET", records[0]["method"])
+	assert.Equal(t, "/api/users/:userId/orders/:orderId", records[0]["route"])
+	assert.Equal(t, float64(200), records[0]["status"])
+	assert.Equal(t, float64(len("order 10")), records[0]["bytes"])
+	assert.Equal(t, "0.0.0.0", records[0]["ip"])
+	assert.Equal(t, "request-1", records[0]["request_id"])
+	assert.Contains(t, records[0], "latency")
+	headers := records[0]["headers"].(map[string]any)
+	assert.Equal(t, logging.Redacted, headers["Authorization"])
+	assert.Equal(t, logging.Redacted, headers["Cookie"])
+	assert.Equal(t, "text/plain", headers["Accept"])
+
+	// status dicatat setelah error diubah menjadi response oleh ErrorHandler
+	assert.Equal(t, "ERROR", records[1]["level"])
+	assert.Equal(t, "/api/error", records[1]["route"])
+	assert.Equal(t, float64(500), records[1]["status"])
+
+	assert.Equal(t, "WARN", records[2]["level"])
+	assert.Equal(t, "unmatched", records[2]["route"])
+}
+
+func TestRequestLoggerSampling(t *testing.T) {
+	cfg := config.Default().Log
+	cfg.SampleRate = 0
+	loggedApp, output := newLoggedApp(t, cfg)
+
+	for i := 0; i < 10; i++ {
+		response, err := loggedApp.Test(httptest.NewRequest("GET", "/api/users/Bagus/orders/10", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, 200, response.StatusCode)
+	}
+	response, err := loggedApp.Test(httptest.NewRequest("GET", "/api/error", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 500, response.StatusCode)
+
+	// request sukses tidak dicatat, request error selalu dicatat
+	records := readLogRecords(t, output)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "/api/error", records[0]["route"])
+	assert.NotContains(t, records[0], "headers")
+}
+
+func TestRequestId(t *testing.T) {
+	queries := &bytes.Buffer{}
+	logger, err := logging.New(config.Default().Log, queries, new(slog.LevelVar))
+	assert.Nil(t, err)
+	databaseConfig := config.Default().Database
+	databaseConfig.LogLevel = "info"
+	tx := testdb.New(t).Session(&gorm.Session{Logger: database.NewLogger(logger, databaseConfig)})
+	userRepository := repository.NewGormStore(tx).Users()
+
+	requestApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	requestApp.Use(middleware.NewRequestId())
+	requestApp.Get("/users/:userId", func(ctx *fiber.Ctx) error {
+		assert.Equal(t, ctx.GetRespHeader("X-Request-ID"), middleware.RequestId(ctx))
+		_, err := userRepository.FindById(ctx.UserContext(), ctx.Params("userId"))
+		if errors.Is(err, repository.ErrNotFound) {
+			return exception.NotFound("user not found")
+		}
+		return err
+	})
+
+	request := httptest.NewRequest("GET", "/users/tidak-ada", nil)
+	request.Header.Set("X-Request-ID", "request-abc")
+	response, err := requestApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+	assert.Equal(t, "request-abc", response.Header.Get("X-Request-ID"))
+
+	// request ID yang sama ada di body error dan di log query GORM
+	problem := exception.Problem{}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&problem))
+	assert.Equal(t, "request-abc", problem.CorrelationId)
+	records := readLogRecords(t, queries)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "request-abc", records[0]["request_id"])
+	assert.Contains(t, records[0]["sql"], "SELECT")
+
+	// ID kosong atau berisi karakter yang tidak valid diganti dengan UUID
+	for _, id := range []string{"", "baris\nlog palsu", strings.Repeat("a", 129)} {
+		request = httptest.NewRequest("GET", "/users/tidak-ada", nil)
+		request.Header.Set("X-Request-ID", id)
+		response, err = requestApp.Test(request)
+		assert.Nil(t, err)
+		generated := response.Header.Get("X-Request-ID")
+		assert.Nil(t, uuid.Validate(generated))
+	}
+
+	request = httptest.NewRequest("GET", "/users/tidak-ada", nil)
+	request.Header.Set("X-Correlation-ID", "correlation-1")
+	response, err = requestApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, "correlation-1", response.Header.Get("X-Request-ID"))
+	assert.Equal(t, "correlation-1", response.Header.Get("X-Correlation-ID"))
+}
+
+func TestDatabaseLogger(t *testing.T) {
+	output := &bytes.Buffer{}
+	logger, err := logging.New(config.Default().Log, output, new(slog.LevelVar))
+	assert.Nil(t, err)
+	cfg := config.Default().Database
+	cfg.LogLevel = "info"
+	cfg.SlowThreshold = 0
+	databaseLogger := database.NewLogger(logger, cfg)
+	userRepository := repository.NewGormStore(testdb.New(t).Session(&gorm.Session{Logger: databaseLogger})).Users()
+	ctx := context.Background()
+
+	user := &entity.User{ID: "logger", Password: "password-rahasia", Name: entity.Name{FirstName: "Logger"}}
+	assert.Nil(t, userRepository.Create(ctx, user))
+	assert.Nil(t, userRepository.UpdatePassword(ctx, user.ID, "password-baru"))
+	_, err = userRepository.FindById(ctx, user.ID)
+	assert.Nil(t, err)
+
+	// nilai password tidak pernah masuk ke log, parameter lain tetap terlihat
+	assert.NotContains(t, output.String(), "password-rahasia")
+	assert.NotContains(t, output.String(), "password-baru")
+	records := readLogRecords(t, output)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, "query", records[0]["msg"])
+	assert.Contains(t, records[0]["sql"], logging.Redacted)
+	assert.Contains(t, records[0]["sql"], "Logger")
+	assert.Equal(t, float64(1), records[0]["rows"])
+	assert.Contains(t, records[1]["sql"], logging.Redacted)
+	assert.Equal(t, float64(1), records[1]["rows"])
+
+	// semua query lebih lama dari 1ns sehingga dicatat sebagai slow query beserta lokasi pemanggilnya
+	cfg.SlowThreshold = time.Nanosecond
+	cfg.LogLevel = "warn"
+	slowRepository := repository.NewGormStore(testdb.New(t).Session(&gorm.Session{Logger: database.NewLogger(logger, cfg)})).Users()
+	_, err = slowRepository.FindById(ctx, "tidak-ada")
+	assert.NotNil(t, err)
+	records = readLogRecords(t, output)
+	assert.Equal(t, 1, len(records))
+	assert.Equal(t, "WARN", records[0]["level"])
+	assert.Equal(t, "slow query", records[0]["msg"])
+	assert.Contains(t, records[0]["caller"], "repository/gorm_repository.go:")
+
+	// level diubah saat berjalan, level silent tidak mencatat apa-apa
+	assert.Equal(t, "info", databaseLogger.Level())
+	assert.Nil(t, databaseLogger.SetLevel("silent"))
+	assert.NotNil(t, databaseLogger.SetLevel("debug"))
+	_, err = userRepository.FindById(ctx, user.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, output.Len())
+}
+
+func TestDatabaseLoggerParamsFilter(t *testing.T) {
+	cfg := config.Default().Database
+	cfg.RedactColumns = "password, Token"
+	databaseLogger := database.NewLogger(slog.Default(), cfg)
+	ctx := context.Background()
+
+	tests := []struct {
+		sql      string
+		params   []interface{}
+		expected []interface{}
+	}{
+		{
+			"INSERT INTO `users` (`id`,`password`,`first_name`) VALUES (?,?,?),(?,?,?)",
+			[]interface{}{"1", "rahasia", "Eko", "2", "rahasia", "Budi"},
+			[]interface{}{"1", logging.Redacted, "Eko", "2", logging.Redacted, "Budi"},
+		},
+		{
+			"UPDATE `users` SET `password`=?,`version`=`version` + ? WHERE id = ? AND `users`.`token` <> ?",
+			[]interface{}{"rahasia", 1, "1", "abc"},
+			[]interface{}{logging.Redacted, 1, "1", logging.Redacted},
+		},
+		{
+			"SELECT * FROM users WHERE name = '?' AND password_hint = ? LIMIT ?",
+			[]interface{}{"petunjuk", 10},
+			[]interface{}{"petunjuk", 10},
+		},
+	}
+	for _, test := range tests {
+		sql, params := databaseLogger.ParamsFilter(ctx, test.sql, test.params...)
+		assert.Equal(t, test.sql, sql)
+		assert.Equal(t, test.expected, params, test.sql)
+	}
+}
+
+func TestLoggingController(t *testing.T) {
+	level := new(slog.LevelVar)
+	_, err := logging.New(config.Default().Log, io.Discard, level)
+	assert.Nil(t, err)
+	databaseLogger := database.NewLogger(slog.Default(), config.Default().Database)
+
+	adminApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	controller.NewLoggingController(level, databaseLogger, validation.New()).Route(adminApp)
+
+	request := httptest.NewRequest("PUT", "/admin/logging", strings.NewReader(`{"level":"debug","database_level":"warn"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := adminApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, slog.LevelDebug, level.Level())
+	assert.Equal(t, "warn", databaseLogger.Level())
+
+	request = httptest.NewRequest("PUT", "/admin/logging", strings.NewReader(`{"database_level":"verbose"}`))
+	request.Header.Set("Content-Type", "application/json")
+	response, err = adminApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 422, response.StatusCode)
+
+	response, err = adminApp.Test(httptest.NewRequest("GET", "/admin/logging", nil))
+	assert.Nil(t, err)
+	body := model.WebResponse[model.LoggingResponse]{}
+	assert.Nil(t, json.NewDecoder(response.Body).Decode(&body))
+	assert.Equal(t, model.LoggingResponse{Level: "debug", DatabaseLevel: "warn"}, body.Data)
+}
@@ -2,27 +2,86 @@ package main
 
 import (
 	// "fmt"
-	"fmt"
-	"time"
+	"context"
+	"log"
+	"log/slog"
+	"os"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/logging"
+	"belajar-golang-fiber/metrics"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/storage"
+	"belajar-golang-fiber/tracing"
+	"belajar-golang-fiber/validation"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+	"go.opentelemetry.io/otel"
 )
 
 func main() {
-	app := fiber.New(fiber.Config{
-		IdleTimeout: time.Second * 5,
-		WriteTimeout: time.Second * 5,
-		ReadTimeout: time.Second * 5,
-		// Prefork: true,
-	})
+	// urutan prioritas: default < config.yaml < env APP_* < flag
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("loaded configuration:\n%s", cfg)
 
-	// Akan berjalan di endpoint yang ada /api nya
-	app.Use("/api",func (ctx *fiber.Ctx) error  {
-		fmt.Println("I'm middleware before processing request")
-		err := ctx.Next()
-		fmt.Println("I'm middleware after processing request")
-		return err
-	})
+	logLevel := new(slog.LevelVar)
+	logger, err := logging.New(cfg.Log, os.Stdout, logLevel)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// log.Printf dari package lain ikut ditulis lewat logger ini
+	slog.SetDefault(logger)
+
+	db, err := database.OpenConnection(cfg.Database)
+	if err != nil {
+		panic(err)
+	}
+
+	fiberConfig := cfg.Server.FiberConfig()
+	fiberConfig.ErrorHandler = exception.ErrorHandler
+	app := fiber.New(fiberConfig)
+
+	// request ID diteruskan lewat ctx.UserContext() sampai ke query GORM dan body error
+	app.Use(middleware.NewRequestId())
+
+	// tracing dipasang sebelum logger supaya log request ikut mencatat trace_id
+	if cfg.Tracing.Exporter != "none" {
+		exporter, err := tracing.NewExporter(context.Background(), cfg.Tracing)
+		if err != nil {
+			panic(err)
+		}
+		tracerProvider := tracing.NewProvider(cfg.Tracing, exporter)
+		defer tracerProvider.Shutdown(context.Background())
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetTextMapPropagator(tracing.Propagator)
+
+		if err := tracing.InstrumentDB(db, tracerProvider); err != nil {
+			panic(err)
+		}
+		app.Use(middleware.NewTracing(tracerProvider))
+	}
+
+	// satu baris log terstruktur untuk setiap request
+	app.Use(middleware.NewRequestLogger(logger, cfg.Log))
+
+	if cfg.Metrics.Enabled {
+		appMetrics := metrics.New()
+		if err := appMetrics.InstrumentDB(db, cfg.Database.Name); err != nil {
+			panic(err)
+		}
+		app.Use(middleware.NewMetrics(appMetrics))
+		app.Get(cfg.Metrics.Path, appMetrics.Handler())
+	}
 
 
 	// if fiber.IsChild() {
@@ -35,7 +94,59 @@ func main() {
         return c.SendString("Hello, World 👋!")
     })
 
-	err := app.Listen("Localhost:3000")
+	hasher := security.NewPasswordHasher(cfg.Security.PasswordCost)
+	validator := validation.New()
+	store := session.New(session.Config{
+		Expiration:     cfg.Security.SessionExpiration,
+		CookieSecure:   cfg.Security.CookieSecure,
+		CookieHTTPOnly: true,
+		CookieSameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	repositories := repository.NewGormStore(db)
+	userService := service.NewUserService(repositories, hasher)
+	authService := service.NewAuthService(repositories, hasher)
+	roleService := service.NewRoleService(repositories)
+	fileStorage, err := storage.New(cfg.Storage)
+	if err != nil {
+		panic(err)
+	}
+	if cfg.Storage.SigningKey == "" {
+		log.Println("storage.signing_key is empty, signed download URLs will not survive a restart")
+	}
+	signer := security.NewURLSigner(cfg.Storage.SigningKey)
+	fileService := service.NewFileService(repositories, fileStorage, signer, cfg.Upload, cfg.Storage)
+	uploadService := service.NewUploadService(repositories, fileService, cfg.Upload)
+	if cfg.Users.PurgeInterval > 0 {
+		go service.RunUserPurge(context.Background(), userService, cfg.Users.PurgeInterval, cfg.Users.DeletedRetention)
+	}
+	if cfg.Upload.PurgeInterval > 0 {
+		go service.RunUploadPurge(context.Background(), uploadService, cfg.Upload.PurgeInterval, cfg.Upload.Expiration)
+	}
+
+	controller.NewAuthController(authService, store, validator).Route(app)
+
+	// setiap group dijaga permission dari role user di database, /users dicek per route di controller
+	api := app.Group("/api")
+	auth := middleware.NewAuth(store)
+	permissions := middleware.NewPermissions(roleService.Permissions)
+	api.Use("/users", auth, permissions)
+	controller.NewUserController(userService, validator).Route(api)
+	api.Use("/files", auth, permissions, middleware.RequireAccess(security.PermFilesRead, security.PermFilesWrite))
+	controller.NewFileController(fileService).Route(api)
+	api.Use("/uploads", auth, permissions, middleware.RequireAccess(security.PermFilesRead, security.PermFilesWrite))
+	controller.NewUploadController(uploadService, cfg.Upload.MaxSize).Route(api)
+	api.Use("/roles", auth, permissions, middleware.Require(security.PermRolesManage))
+	controller.NewRoleController(roleService, validator).Route(api)
+	// logger GORM dibuat oleh database.Open, levelnya bisa diubah lewat endpoint ini
+	databaseLogger, ok := db.Logger.(*database.Logger)
+	if !ok {
+		log.Fatalf("unexpected GORM logger %T, runtime log level endpoint needs *database.Logger", db.Logger)
+	}
+	api.Use("/admin/logging", auth, permissions, middleware.Require(security.PermLogsManage))
+	controller.NewLoggingController(logLevel, databaseLogger, validator).Route(api)
+
+	err = app.Listen(cfg.Server.Address)
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/metrics"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	appMetrics := metrics.New()
+	tx := testdb.New(t)
+	assert.Nil(t, appMetrics.InstrumentDB(tx, "test"))
+	userRepository := repository.NewGormStore(tx).Users()
+
+	metricsApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	metricsApp.Use(middleware.NewMetrics(appMetrics))
+	metricsApp.Get("/metrics", appMetrics.Handler())
+	metricsApp.Get("/api/users/:userId/orders/:orderId", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("order " + ctx.Params("orderId"))
+	})
+	metricsApp.Get("/api/users/:userId", func(ctx *fiber.Ctx) error {
+		_, err := userRepository.FindById(ctx.UserContext(), ctx.Params("userId"))
+		return err
+	})
+
+	for _, path := range []string{"/api/users/Bagus/orders/10", "/api/users/Budi/orders/11", "/api/users/Bagus", "/tidak-ada"} {
+		_, err := metricsApp.Test(httptest.NewRequest("GET", path, nil))
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, tx.Exec("select 1").Error)
+	_, err := userRepository.FindById(context.Background(), "tidak-ada")
+	assert.NotNil(t, err)
+
+	response, err := metricsApp.Test(httptest.NewRequest("GET", "/metrics", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	body := string(bytes)
+
+	// label route memakai template, ID dari URL tidak pernah menjadi label
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/api/users/:userId/orders/:orderId",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/api/users/:userId",status="500"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.Contains(t, body, `http_request_duration_seconds_count{method="GET",route="/api/users/:userId/orders/:orderId",status="200"} 2`)
+	assert.NotContains(t, body, "Bagus")
+	assert.Contains(t, body, "http_requests_in_flight 1")
+
+	assert.Contains(t, body, `db_query_duration_seconds_count{operation="query",table="users"} 2`)
+	assert.Contains(t, body, `db_query_duration_seconds_count{operation="raw",table="unknown"} 1`)
+	assert.Contains(t, body, `go_sql_open_connections{db_name="test"}`)
+	assert.Contains(t, body, `go_sql_wait_count_total{db_name="test"}`)
+	assert.Contains(t, body, "go_goroutines")
+}
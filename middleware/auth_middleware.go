@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"belajar-golang-fiber/exception"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/session"
+)
+
+// SessionUserKey adalah key di session dan ctx.Locals untuk menyimpan ID user yang sedang login
+const SessionUserKey = "user_id"
+
+// NewAuth hanya meneruskan request yang memiliki session login yang valid
+func NewAuth(store *session.Store) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		sess, err := store.Get(ctx)
+		if err != nil {
+			return err
+		}
+
+		userId, ok := sess.Get(SessionUserKey).(string)
+		if !ok || userId == "" {
+			return exception.Unauthorized("login required")
+		}
+
+		ctx.Locals(SessionUserKey, userId)
+		return ctx.Next()
+	}
+}
+
+// CurrentUserId mengambil ID user yang disimpan oleh middleware NewAuth
+func CurrentUserId(ctx *fiber.Ctx) string {
+	userId, _ := ctx.Locals(SessionUserKey).(string)
+	return userId
+}
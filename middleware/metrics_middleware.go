@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"time"
+
+	"belajar-golang-fiber/metrics"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewMetrics mencatat jumlah, lama dan request yang sedang berjalan per method, template route dan status
+func NewMetrics(m *metrics.Metrics) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		self, start := ctx.Route(), time.Now()
+		m.RequestStarted()
+		handleError(ctx, ctx.Next())
+		m.RequestFinished(ctx.Method(), routeTemplate(ctx, self), ctx.Response().StatusCode(), time.Since(start))
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"context"
+
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/security"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionsKey adalah key di ctx.Locals untuk menyimpan permission user yang sedang login
+const PermissionsKey = "permissions"
+
+// PermissionLoader mengambil gabungan permission dari semua role milik user
+type PermissionLoader func(ctx context.Context, userId string) ([]string, error)
+
+// NewPermissions memuat permission user yang login, dipasang setelah NewAuth
+func NewPermissions(loader PermissionLoader) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		permissions, err := loader(ctx.UserContext(), CurrentUserId(ctx))
+		if err != nil {
+			return err
+		}
+
+		ctx.Locals(PermissionsKey, security.PermissionSet(permissions))
+		return ctx.Next()
+	}
+}
+
+// Require hanya meneruskan request dari user yang memiliki semua permission, bisa dipasang
+// di group (contoh api.Use("/roles", ..., Require(security.PermRolesManage))) atau per route
+func Require(permissions ...string) fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		for _, permission := range permissions {
+			if !Can(ctx, permission) {
+				return exception.Forbidden("permission " + permission + " required")
+			}
+		}
+		return ctx.Next()
+	}
+}
+
+// RequireAccess memakai permission read untuk GET, HEAD dan OPTIONS, selain itu permission write
+func RequireAccess(read string, write string) fiber.Handler {
+	readHandler, writeHandler := Require(read), Require(write)
+	return func(ctx *fiber.Ctx) error {
+		switch ctx.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return readHandler(ctx)
+		default:
+			return writeHandler(ctx)
+		}
+	}
+}
+
+// CurrentPermissions mengambil permission yang dimuat oleh NewPermissions, kosong jika belum dimuat
+func CurrentPermissions(ctx *fiber.Ctx) security.PermissionSet {
+	permissions, _ := ctx.Locals(PermissionsKey).(security.PermissionSet)
+	return permissions
+}
+
+func Can(ctx *fiber.Ctx, permission string) bool {
+	return CurrentPermissions(ctx).Has(permission)
+}
+
+// Authorize adalah policy untuk aturan per data di dalam handler: diizinkan jika user memiliki permission
+// atau user tersebut pemilik datanya, contoh user hanya boleh mengubah dirinya sendiri:
+//
+//	middleware.Authorize(ctx, security.PermUsersWrite, ctx.Params("userId"))
+func Authorize(ctx *fiber.Ctx, permission string, ownerId string) error {
+	if Can(ctx, permission) || (ownerId != "" && ownerId == CurrentUserId(ctx)) {
+		return nil
+	}
+	return exception.Forbidden("permission " + permission + " required")
+}
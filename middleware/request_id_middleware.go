@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"strings"
+
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/logging"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// NewRequestId memakai X-Request-ID dari client (atau X-Correlation-ID) jika valid, selain itu membuat
+// UUID baru. ID disimpan di ctx.UserContext() dan dikirim balik di header response
+func NewRequestId() fiber.Handler {
+	return func(ctx *fiber.Ctx) error {
+		id := ctx.Get(fiber.HeaderXRequestID)
+		if id == "" {
+			// X-Correlation-ID masih diterima sebagai request ID untuk client lama
+			id = ctx.Get(exception.HeaderCorrelationId)
+		}
+		if logging.ValidRequestId(id) {
+			// string dari header memakai buffer fasthttp yang dipakai ulang setelah request selesai
+			id = strings.Clone(id)
+		} else {
+			id = uuid.NewString()
+		}
+
+		ctx.SetUserContext(logging.WithRequestId(ctx.UserContext(), id))
+		ctx.Set(fiber.HeaderXRequestID, id)
+		return ctx.Next()
+	}
+}
+
+// RequestId mengambil request ID yang disimpan oleh NewRequestId
+func RequestId(ctx *fiber.Ctx) string {
+	return logging.RequestId(ctx.UserContext())
+}
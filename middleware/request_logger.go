@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/logging"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// NewRequestLogger mencatat satu record per request setelah handler selesai. Route dicatat dalam bentuk
+// template (contoh /api/users/:userId) supaya log mudah dikelompokkan, request sukses bisa di-sampling
+// dengan cfg.SampleRate sedangkan request dengan status >= 400 selalu dicatat. Dipasang setelah NewRequestId,
+// request_id ditambahkan oleh logger dari logging.New
+func NewRequestLogger(logger *slog.Logger, cfg config.LogConfig) fiber.Handler {
+	redacted := cfg.Redacted()
+	for i, header := range redacted {
+		redacted[i] = http.CanonicalHeaderKey(header)
+	}
+
+	return func(ctx *fiber.Ctx) error {
+		self, start := ctx.Route(), time.Now()
+		handleError(ctx, ctx.Next())
+		latency := time.Since(start)
+
+		status := ctx.Response().StatusCode()
+		if status < fiber.StatusBadRequest && cfg.SampleRate < 1 && rand.Float64() >= cfg.SampleRate {
+			return nil
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Method()),
+			slog.String("route", routeTemplate(ctx, self)),
+			slog.Int("status", status),
+			slog.Duration("latency", latency),
+			slog.Int("bytes", responseSize(ctx)),
+			slog.String("ip", ctx.IP()),
+		}
+		if cfg.Headers {
+			attrs = append(attrs, slog.Any("headers", requestHeaders(ctx, redacted)))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= fiber.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= fiber.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(ctx.UserContext(), level, "request", attrs...)
+		return nil
+	}
+}
+
+// routeTemplate mengambil path route terakhir yang menangani request, request yang ditolak middleware
+// (contoh 401 dari NewAuth) tercatat dengan path mount middleware tersebut. Jika route masih sama dengan
+// route logger berarti tidak ada route yang cocok (404), path asli tidak dicatat supaya jumlah nilainya terbatas
+func routeTemplate(ctx *fiber.Ctx, self *fiber.Route) string {
+	route := ctx.Route()
+	if route == nil || route == self {
+		return "unmatched"
+	}
+	return route.Path
+}
+
+// handleError mengubah error menjadi response di middleware (bukan di akhir oleh fiber) supaya status
+// yang dicatat sama dengan yang diterima client
+func handleError(ctx *fiber.Ctx, err error) {
+	if err == nil {
+		return
+	}
+	if err := ctx.App().ErrorHandler(ctx, err); err != nil {
+		_ = ctx.SendStatus(fiber.StatusInternalServerError)
+	}
+}
+
+func responseSize(ctx *fiber.Ctx) int {
+	response := ctx.Response()
+	if response.IsBodyStream() {
+		// body stream belum dibaca, pakai Content-Length (-1 jika tidak diketahui)
+		return response.Header.ContentLength()
+	}
+	return len(response.Body())
+}
+
+func requestHeaders(ctx *fiber.Ctx, redacted []string) map[string]string {
+	headers := map[string]string{}
+	for key, values := range ctx.GetReqHeaders() {
+		if slices.Contains(redacted, http.CanonicalHeaderKey(key)) {
+			headers[key] = logging.Redacted
+			continue
+		}
+		headers[key] = strings.Join(values, ", ")
+	}
+	return headers
+}
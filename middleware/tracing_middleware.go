@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"belajar-golang-fiber/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// NewTracing membuka span server untuk setiap request, melanjutkan trace dari header traceparent
+// jika ada. Span disimpan di ctx.UserContext() sehingga query GORM dan request keluar menjadi child-nya
+func NewTracing(provider trace.TracerProvider) fiber.Handler {
+	tracer := provider.Tracer(tracing.InstrumentationName)
+	return func(ctx *fiber.Ctx) error {
+		parent := tracing.Propagator.Extract(ctx.UserContext(), tracing.HeaderCarrier{Header: &ctx.Request().Header})
+		// nilai dari fiber memakai buffer yang dipakai ulang, span baru dikirim setelah request selesai
+		method := strings.Clone(ctx.Method())
+		spanCtx, span := tracer.Start(parent, method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(method),
+				semconv.URLPath(strings.Clone(ctx.Path())),
+				semconv.ClientAddress(strings.Clone(ctx.IP())),
+			),
+		)
+		defer span.End()
+
+		self := ctx.Route()
+		ctx.SetUserContext(spanCtx)
+		handleError(ctx, ctx.Next())
+
+		route, status := routeTemplate(ctx, self), ctx.Response().StatusCode()
+		span.SetName(method + " " + route)
+		span.SetAttributes(semconv.HTTPRoute(route), semconv.HTTPResponseStatusCode(status))
+		if status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+package migration
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Files berisi semua file migration di folder sql, ikut ter-embed ke binary
+//
+//go:embed sql/*.sql
+var Files embed.FS
+
+// Dir adalah lokasi folder migration relatif dari root project, dipakai oleh perintah create
+const Dir = "migration/sql"
+
+const DefaultTable = "schema_migrations"
+
+// nama file: <version>_<name>.up.sql dan <version>_<name>.down.sql
+var filePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+var nameReplacer = regexp.MustCompile(`[^a-z0-9]+`)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+type Migrator struct {
+	DB     *gorm.DB
+	Source fs.FS
+	Table  string
+}
+
+// New membuat Migrator yang membaca file *.sql dari source (biasanya migration.Files)
+func New(db *gorm.DB, source fs.FS) *Migrator {
+	return &Migrator{DB: db, Source: source, Table: DefaultTable}
+}
+
+// Load membaca semua migration dan mengurutkannya berdasarkan version
+func (m *Migrator) Load() ([]Migration, error) {
+	byVersion := map[int64]*Migration{}
+	err := fs.WalkDir(m.Source, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(m.Source, path)
+		if err != nil {
+			return err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return fmt.Errorf("migration: version %d is used by %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" {
+			return nil, fmt.Errorf("migration: %06d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+type appliedMigration struct {
+	Version   int64
+	Name      string
+	AppliedAt time.Time
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.DB.Exec("create table if not exists " + m.Table +
+		" (version bigint not null, name varchar(255) not null, applied_at timestamp not null default current_timestamp, primary key (version))").Error
+}
+
+func (m *Migrator) applied() (map[int64]appliedMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	var rows []appliedMigration
+	if err := m.DB.Table(m.Table).Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	result := map[int64]appliedMigration{}
+	for _, row := range rows {
+		result[row.Version] = row
+	}
+	return result, nil
+}
+
+// Status menampilkan semua migration beserta informasi sudah dijalankan atau belum
+func (m *Migrator) Status() ([]Status, error) {
+	migrations, err := m.Load()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = Status{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			statuses[i].Applied = true
+			statuses[i].AppliedAt = &row.AppliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Up menjalankan semua migration yang belum dijalankan secara berurutan
+func (m *Migrator) Up() ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, status := range statuses {
+		if status.Applied {
+			continue
+		}
+		if err := m.run(status.Migration.Up); err != nil {
+			return done, fmt.Errorf("migration: %06d_%s up: %w", status.Version, status.Name, err)
+		}
+		err := m.DB.Table(m.Table).Create(map[string]interface{}{
+			"version":    status.Version,
+			"name":       status.Name,
+			"applied_at": time.Now(),
+		}).Error
+		if err != nil {
+			return done, err
+		}
+		done = append(done, status.Migration)
+	}
+	return done, nil
+}
+
+// Down membatalkan sejumlah steps migration terakhir yang sudah dijalankan
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(statuses) - 1; i >= 0 && len(done) < steps; i-- {
+		status := statuses[i]
+		if !status.Applied {
+			continue
+		}
+		if status.Down == "" {
+			return done, fmt.Errorf("migration: %06d_%s has no down file", status.Version, status.Name)
+		}
+		if err := m.run(status.Down); err != nil {
+			return done, fmt.Errorf("migration: %06d_%s down: %w", status.Version, status.Name, err)
+		}
+		if err := m.DB.Exec("delete from "+m.Table+" where version = ?", status.Version).Error; err != nil {
+			return done, err
+		}
+		done = append(done, status.Migration)
+	}
+	return done, nil
+}
+
+// run menjalankan isi file migration satu statement per satu statement,
+// karena driver MySQL secara default tidak mengizinkan multi statement
+func (m *Migrator) run(content string) error {
+	for _, statement := range SplitStatements(content) {
+		if err := m.DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SplitStatements memecah isi file SQL berdasarkan ; di akhir baris dan membuang komentar --
+func SplitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// Create membuat pasangan file up/down kosong dengan version berikutnya di folder dir
+func Create(dir string, name string) (string, string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = nameReplacer.ReplaceAllString(name, "_")
+	name = strings.Trim(name, "_")
+	if name == "" {
+		return "", "", errors.New("migration: name is required")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	var last int64
+	for _, entry := range entries {
+		if match := filePattern.FindStringSubmatch(entry.Name()); match != nil {
+			version, _ := strconv.ParseInt(match[1], 10, 64)
+			if version > last {
+				last = version
+			}
+		}
+	}
+
+	prefix := filepath.Join(dir, fmt.Sprintf("%06d_%s", last+1, name))
+	up, down := prefix+".up.sql", prefix+".down.sql"
+	if err := os.WriteFile(up, []byte("-- tulis perintah SQL untuk migrate up di sini\n"), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(down, []byte("-- tulis perintah SQL untuk membatalkan migration di sini\n"), 0644); err != nil {
+		return "", "", err
+	}
+	return up, down, nil
+}
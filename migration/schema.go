@@ -0,0 +1,52 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+
+	"belajar-golang-fiber/entity"
+
+	"gorm.io/gorm"
+)
+
+// Models adalah semua model GORM yang tabelnya dibuat lewat migration
+var Models = []interface{}{
+	&entity.User{},
+	&entity.UserLogs{},
+	&entity.File{},
+	&entity.Upload{},
+	&entity.Role{},
+	&entity.RolePermission{},
+	&entity.UserRole{},
+}
+
+// CheckSchema memastikan setiap tabel dan kolom yang dipakai model GORM ada di database,
+// dipakai setelah migrate up untuk mendeteksi migration yang tidak sinkron dengan entity
+func CheckSchema(db *gorm.DB, models ...interface{}) error {
+	var errs []error
+	for _, model := range models {
+		statement := &gorm.Statement{DB: db}
+		if err := statement.Parse(model); err != nil {
+			return err
+		}
+
+		table := statement.Schema.Table
+		if !db.Migrator().HasTable(table) {
+			errs = append(errs, fmt.Errorf("table %s does not exist", table))
+			continue
+		}
+		for _, field := range statement.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !db.Migrator().HasColumn(model, field.DBName) {
+				errs = append(errs, fmt.Errorf("column %s.%s does not exist", table, field.DBName))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("migration: schema does not match models: %w", errors.Join(errs...))
+	}
+	return nil
+}
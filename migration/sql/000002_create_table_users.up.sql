@@ -0,0 +1 @@
+create table users ( id varchar(100) not NULL, password varchar(100) not NULL, name varchar(255) not NULL, created_at timestamp not NULL default current_timestamp, updated_at timestamp not NULL default current_timestamp on update current_timestamp, primary key (id)) engine=INNODB;
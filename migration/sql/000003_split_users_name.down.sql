@@ -0,0 +1,3 @@
+alter table users drop column last_name;
+alter table users drop column middle_name;
+alter table users rename column first_name to name;
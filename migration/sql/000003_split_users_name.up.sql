@@ -0,0 +1,3 @@
+alter table users rename column name to first_name;
+alter table users add column middle_name varchar(100) NULL after first_name;
+alter table users add column last_name varchar(100) NULL after middle_name;
@@ -0,0 +1 @@
+create table user_logs(id int auto_increment, user_id varchar(100) not NULL, action varchar(100) not NULL, created_at timestamp not null default current_timestamp, updated_at timestamp not null default current_timestamp on update current_timestamp, primary key (id)) engine=InnoDB;
@@ -0,0 +1 @@
+alter table user_logs drop column changes;
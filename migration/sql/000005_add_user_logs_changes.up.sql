@@ -0,0 +1 @@
+alter table user_logs add column changes text NULL after action;
@@ -0,0 +1 @@
+create table files(id varchar(100) not NULL, original_name varchar(255) not NULL, storage_name varchar(255) not NULL, content_type varchar(100) not NULL, size bigint not NULL, checksum char(64) not NULL, uploaded_by varchar(100) not NULL, created_at timestamp not null default current_timestamp, updated_at timestamp not null default current_timestamp on update current_timestamp, primary key (id), unique key files_storage_name_unique (storage_name), key files_uploaded_by_index (uploaded_by)) engine=InnoDB;
@@ -0,0 +1 @@
+create table uploads(id varchar(100) not NULL, name varchar(255) not NULL, upload_length bigint not NULL, upload_offset bigint not NULL default 0, uploaded_by varchar(100) not NULL, created_at timestamp not null default current_timestamp, updated_at timestamp not null default current_timestamp on update current_timestamp, primary key (id), key uploads_uploaded_by_index (uploaded_by)) engine=InnoDB;
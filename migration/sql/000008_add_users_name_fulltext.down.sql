@@ -0,0 +1 @@
+alter table users drop index users_name_fulltext;
@@ -0,0 +1 @@
+alter table users add fulltext index users_name_fulltext (first_name, middle_name, last_name);
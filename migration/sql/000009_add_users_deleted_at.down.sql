@@ -0,0 +1,2 @@
+drop index users_deleted_at_index on users;
+alter table users drop column deleted_at;
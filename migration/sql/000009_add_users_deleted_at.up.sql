@@ -0,0 +1,2 @@
+alter table users add column deleted_at timestamp NULL;
+create index users_deleted_at_index on users (deleted_at);
@@ -0,0 +1 @@
+alter table users add column version bigint not NULL default 1 after last_name;
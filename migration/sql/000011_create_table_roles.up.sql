@@ -0,0 +1,7 @@
+create table roles(id varchar(100) not NULL, description varchar(255) not NULL default '', created_at timestamp not null default current_timestamp, updated_at timestamp not null default current_timestamp on update current_timestamp, primary key (id)) engine=InnoDB;
+create table role_permissions(role_id varchar(100) not NULL, permission varchar(100) not NULL, primary key (role_id, permission)) engine=InnoDB;
+create table user_roles(user_id varchar(100) not NULL, role_id varchar(100) not NULL, created_at timestamp not null default current_timestamp, primary key (user_id, role_id), key user_roles_role_id_index (role_id)) engine=InnoDB;
+-- role bawaan, user yang sudah ada mendapat role user supaya tetap bisa mengakses API
+insert into roles(id, description) values ('admin', 'Akses penuh'), ('user', 'Role default setiap user');
+insert into role_permissions(role_id, permission) values ('admin', '*'), ('user', 'users:read'), ('user', 'files:read'), ('user', 'files:write');
+insert into user_roles(user_id, role_id) select id, 'user' from users;
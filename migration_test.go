@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/migration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrationFiles(t *testing.T) {
+	migrations, err := migration.New(db, migration.Files).Load()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, migrations)
+
+	// version harus berurutan tanpa lubang dan setiap migration bisa di-rollback
+	for i, m := range migrations {
+		assert.Equal(t, int64(i+1), m.Version)
+		assert.NotEqual(t, "", m.Up)
+		assert.NotEqual(t, "", m.Down)
+		for _, content := range []string{m.Up, m.Down} {
+			statements := migration.SplitStatements(content)
+			assert.NotEmpty(t, statements, m.Name)
+			for _, statement := range statements {
+				assert.Nil(t, checkStatement(statement), "%06d_%s: %s", m.Version, m.Name, statement)
+			}
+		}
+	}
+	assert.Equal(t, "split_users_name", migrations[2].Name)
+	assert.Equal(t, 3, len(migration.SplitStatements(migrations[2].Up)))
+}
+
+var statementKeywords = []string{"create", "alter", "drop", "insert", "update", "delete", "rename"}
+
+// checkStatement memeriksa bentuk statement tanpa server MySQL: diawali keyword DDL/DML,
+// hanya satu statement, serta kurung dan tanda kutip seimbang
+func checkStatement(statement string) error {
+	fields := strings.Fields(strings.ToLower(statement))
+	if len(fields) == 0 {
+		return errors.New("empty statement")
+	}
+	known := false
+	for _, keyword := range statementKeywords {
+		known = known || fields[0] == keyword
+	}
+	if !known {
+		return fmt.Errorf("unknown statement %q", fields[0])
+	}
+
+	depth := 0
+	var quote rune
+	for _, char := range statement {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+		case char == '\'' || char == '"' || char == '`':
+			quote = char
+		case char == '(':
+			depth++
+		case char == ')':
+			depth--
+			if depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+		case char == ';':
+			return errors.New("more than one statement, put each statement on its own line ending with ;")
+		}
+	}
+	if quote != 0 {
+		return fmt.Errorf("unterminated %c quote", quote)
+	}
+	if depth != 0 {
+		return errors.New("unbalanced parentheses")
+	}
+	return nil
+}
+
+// TestMigrationMySQL menjalankan SQL migration yang sebenarnya, hanya dengan APP_TEST_DATABASE=mysql
+// karena file migration memakai sintaks MySQL. Database test di-rollback lalu di-migrate ulang.
+func TestMigrationMySQL(t *testing.T) {
+	if os.Getenv(testdb.EnvDriver) != "mysql" {
+		t.Skipf("set %s=mysql to apply the migration files", testdb.EnvDriver)
+	}
+	mysql, err := testdb.Open()
+	assert.Nil(t, err)
+	migrator := migration.New(mysql, migration.Files)
+	migrations, err := migrator.Load()
+	assert.Nil(t, err)
+
+	_, err = migrator.Down(len(migrations))
+	assert.Nil(t, err)
+	statuses, err := migrator.Status()
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.False(t, status.Applied, status.Name)
+	}
+
+	done, err := migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, len(migrations), len(done))
+	statuses, err = migrator.Status()
+	assert.Nil(t, err)
+	for _, status := range statuses {
+		assert.True(t, status.Applied, status.Name)
+	}
+	assert.Nil(t, migration.CheckSchema(mysql, migration.Models...))
+}
+
+func TestMigrationUpDownStatus(t *testing.T) {
+	migrator := migration.New(db, fstest.MapFS{
+		"000001_create_a.up.sql":   {Data: []byte("-- tabel a\ncreate table migration_test_a (id varchar(100) not null, primary key (id));")},
+		"000001_create_a.down.sql": {Data: []byte("drop table migration_test_a;")},
+		"000002_create_b.up.sql":   {Data: []byte("create table migration_test_b (id varchar(100) not null);\ninsert into migration_test_b (id) values ('1');")},
+		"000002_create_b.down.sql": {Data: []byte("drop table migration_test_b;")},
+	})
+	migrator.Table = "schema_migrations_test"
+	defer db.Exec("drop table if exists schema_migrations_test")
+
+	done, err := migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(done))
+	assert.True(t, db.Migrator().HasTable("migration_test_b"))
+
+	// menjalankan up lagi tidak melakukan apa-apa
+	done, err = migrator.Up()
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(done))
+
+	done, err = migrator.Down(1)
+	assert.Nil(t, err)
+	assert.Equal(t, "create_b", done[0].Name)
+	assert.False(t, db.Migrator().HasTable("migration_test_b"))
+
+	statuses, err := migrator.Status()
+	assert.Nil(t, err)
+	assert.True(t, statuses[0].Applied)
+	assert.NotNil(t, statuses[0].AppliedAt)
+	assert.False(t, statuses[1].Applied)
+
+	done, err = migrator.Down(5)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(done))
+	assert.False(t, db.Migrator().HasTable("migration_test_a"))
+}
+
+func TestMigrationCreate(t *testing.T) {
+	dir := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "000007_lama.up.sql"), nil, 0644))
+
+	up, down, err := migration.Create(dir, "Add Deleted At")
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(dir, "000008_add_deleted_at.up.sql"), up)
+	assert.Equal(t, filepath.Join(dir, "000008_add_deleted_at.down.sql"), down)
+
+	_, _, err = migration.Create(dir, "  ")
+	assert.NotNil(t, err)
+}
+
+type userWithUnknownColumn struct {
+	ID      string `gorm:"column:id"`
+	Unknown string `gorm:"column:kolom_tidak_ada"`
+}
+
+func (userWithUnknownColumn) TableName() string {
+	return "users"
+}
+
+func TestMigrationCheckSchema(t *testing.T) {
+	assert.Nil(t, migration.CheckSchema(db, migration.Models...))
+
+	err := migration.CheckSchema(db, &userWithUnknownColumn{})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "users.kolom_tidak_ada")
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"belajar-golang-fiber/entity"
+)
+
+type LoginRequest struct {
+	Username string `json:"username" xml:"username" form:"username" validate:"required,max=100"`
+	Password string `json:"password" xml:"password" form:"password" validate:"required,maxbytes=72"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username" xml:"username" form:"username" validate:"required,min=3,max=100,pattern=username"`
+	Password string `json:"password" xml:"password" form:"password" validate:"required,min=8,maxbytes=72"`
+	Name     string `json:"name" xml:"name" form:"name" validate:"required,max=255"`
+}
+
+type RegisterResponse struct {
+	XMLName    xml.Name `json:"-" xml:"RegisterResponse"`
+	Username   string   `json:"username" xml:"username" form:"username"`
+	FirstName  string   `json:"first_name" xml:"first_name" form:"first_name"`
+	MiddleName string   `json:"middle_name" xml:"middle_name" form:"middle_name"`
+	LastName   string   `json:"last_name" xml:"last_name" form:"last_name"`
+}
+
+// ParseName memecah nama lengkap menjadi first, middle dan last name.
+// Kata pertama menjadi first name, kata terakhir menjadi last name, sisanya middle name
+func ParseName(fullName string) entity.Name {
+	words := strings.Fields(fullName)
+	switch len(words) {
+	case 0:
+		return entity.Name{}
+	case 1:
+		return entity.Name{FirstName: words[0]}
+	default:
+		return entity.Name{
+			FirstName:  words[0],
+			MiddleName: strings.Join(words[1:len(words)-1], " "),
+			LastName:   words[len(words)-1],
+		}
+	}
+}
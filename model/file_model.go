@@ -0,0 +1,48 @@
+package model
+
+import (
+	"io"
+	"time"
+
+	"belajar-golang-fiber/entity"
+)
+
+// UploadFile adalah satu file yang akan di-upload, Reader dibaca bertahap (tidak dimuat semua ke memory)
+type UploadFile struct {
+	Name   string
+	Reader io.Reader
+}
+
+type FileResponse struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	UploadedBy  string    `json:"uploaded_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// FileContent adalah metadata file beserta cara membaca isinya, seluruhnya atau sebagian (Range)
+type FileContent struct {
+	FileResponse
+	// Open membaca length byte mulai dari offset, pemanggil wajib menutup reader
+	Open func(offset int64, length int64) (io.ReadCloser, error)
+}
+
+type DownloadURLResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func ToFileResponse(file *entity.File) FileResponse {
+	return FileResponse{
+		ID:          file.ID,
+		Name:        file.OriginalName,
+		ContentType: file.ContentType,
+		Size:        file.Size,
+		Checksum:    file.Checksum,
+		UploadedBy:  file.UploadedBy,
+		CreatedAt:   file.CreatedAt,
+	}
+}
@@ -0,0 +1,13 @@
+package model
+
+// LoggingResponse berisi level log aplikasi dan level log query GORM yang sedang aktif
+type LoggingResponse struct {
+	Level         string `json:"level"`
+	DatabaseLevel string `json:"database_level"`
+}
+
+// UpdateLoggingRequest mengubah level log, field yang kosong tidak diubah
+type UpdateLoggingRequest struct {
+	Level         string `json:"level" validate:"omitempty,oneof=debug info warn error"`
+	DatabaseLevel string `json:"database_level" validate:"omitempty,oneof=silent error warn info"`
+}
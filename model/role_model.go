@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"belajar-golang-fiber/entity"
+)
+
+type RoleResponse struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CreateRoleRequest struct {
+	ID          string   `json:"id" validate:"required,max=100,pattern=username"`
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required,max=100"`
+}
+
+// UpdateRoleRequest mengganti description dan seluruh permission role
+type UpdateRoleRequest struct {
+	Description string   `json:"description" validate:"max=255"`
+	Permissions []string `json:"permissions" validate:"dive,required,max=100"`
+}
+
+func ToRoleResponse(role *entity.Role) RoleResponse {
+	permissions := make([]string, len(role.Permissions))
+	for i, permission := range role.Permissions {
+		permissions[i] = permission.Permission
+	}
+	return RoleResponse{
+		ID:          role.ID,
+		Description: role.Description,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"belajar-golang-fiber/entity"
+)
+
+type CreateUploadRequest struct {
+	Name   string
+	Length int64
+}
+
+type UploadResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Length int64  `json:"length"`
+	Offset int64  `json:"offset"`
+	// ExpiresAt adalah batas waktu melanjutkan upload, mundur lagi setiap ada data yang diterima
+	ExpiresAt time.Time `json:"expires_at"`
+	// File terisi setelah semua data diterima dan file tersimpan
+	File *FileResponse `json:"file,omitempty"`
+}
+
+func ToUploadResponse(upload *entity.Upload) UploadResponse {
+	return UploadResponse{
+		ID:     upload.ID,
+		Name:   upload.Name,
+		Length: upload.Length,
+		Offset: upload.Offset,
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"belajar-golang-fiber/audit"
+	"belajar-golang-fiber/entity"
+)
+
+type UserLogResponse struct {
+	ID        string         `json:"id"`
+	UserId    string         `json:"user_id"`
+	Action    string         `json:"action"`
+	Changes   []audit.Change `json:"changes"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+func ToUserLogResponse(log *entity.UserLogs) UserLogResponse {
+	return UserLogResponse{
+		ID:        log.ID,
+		UserId:    log.UserId,
+		Action:    log.Action,
+		Changes:   audit.Changes(log),
+		CreatedAt: log.CreatedAt,
+	}
+}
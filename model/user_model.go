@@ -0,0 +1,88 @@
+package model
+
+import (
+	"time"
+
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/query"
+)
+
+// UserQuery adalah field yang boleh dipakai untuk filter dan sort di GET /api/users
+var UserQuery = &query.Schema{
+	Fields: map[string]query.Field{
+		"id":          {Column: "id", Type: query.String, Sortable: true, Filterable: true},
+		"first_name":  {Column: "first_name", Type: query.String, Sortable: true, Filterable: true},
+		"middle_name": {Column: "middle_name", Type: query.String, Sortable: true, Filterable: true},
+		"last_name":   {Column: "last_name", Type: query.String, Sortable: true, Filterable: true},
+		"created_at":  {Column: "created_at", Type: query.Time, Sortable: true, Filterable: true},
+		"updated_at":  {Column: "updated_at", Type: query.Time, Sortable: true, Filterable: true},
+	},
+	Key:          "id",
+	DefaultSort:  []query.Sort{{Field: "id"}},
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
+type UserResponse struct {
+	ID        string      `json:"id"`
+	Name      entity.Name `json:"name"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	// Version sama dengan header ETag, kirim kembali lewat If-Match saat update
+	Version int64 `json:"version"`
+	// DeletedAt hanya terisi untuk user yang sudah dihapus (list dengan include_deleted)
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+}
+
+// SearchUserRequest adalah query string GET /api/users/search?q=&limit=
+type SearchUserRequest struct {
+	Query string `json:"q" query:"q" validate:"required,max=100"`
+	Limit int    `json:"limit" query:"limit" validate:"omitempty,min=1,max=100"`
+}
+
+type UserSearchResponse struct {
+	UserResponse
+	Score float64 `json:"score"`
+	// Highlight berisi field nama yang cocok, kata yang ditemukan dibungkus <em></em>
+	Highlight map[string]string `json:"highlight"`
+}
+
+type CreateUserRequest struct {
+	ID       string      `json:"id" validate:"required,max=100,pattern=username"`
+	Password string      `json:"password" validate:"required,min=8,maxbytes=72"`
+	Name     NameRequest `json:"name"`
+}
+
+// NameRequest adalah nama user pada body request, aturan validasinya di sini bukan di entity.Name
+type NameRequest struct {
+	FirstName  string `json:"first_name" validate:"required,max=255"`
+	MiddleName string `json:"middle_name" validate:"max=100"`
+	LastName   string `json:"last_name" validate:"max=100"`
+}
+
+// UpdateUserRequest memakai pointer supaya bisa membedakan field yang tidak dikirim
+// dengan field yang sengaja dikosongkan (partial update)
+type UpdateUserRequest struct {
+	Password *string            `json:"password" validate:"omitnil,min=8,maxbytes=72"`
+	Name     *UpdateNameRequest `json:"name"`
+}
+
+type UpdateNameRequest struct {
+	FirstName  *string `json:"first_name" validate:"omitnil,min=1,max=255"`
+	MiddleName *string `json:"middle_name" validate:"omitnil,max=100"`
+	LastName   *string `json:"last_name" validate:"omitnil,max=100"`
+}
+
+func ToUserResponse(user *entity.User) UserResponse {
+	response := UserResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		Version:   user.Version,
+	}
+	if user.DeletedAt.Valid {
+		response.DeletedAt = &user.DeletedAt.Time
+	}
+	return response
+}
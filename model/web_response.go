@@ -0,0 +1,33 @@
+package model
+
+import "belajar-golang-fiber/query"
+
+// WebResponse adalah format standar response JSON dari API
+type WebResponse[T any] struct {
+	Data   T             `json:"data"`
+	Paging *PageMetadata `json:"paging,omitempty"`
+}
+
+type PageMetadata struct {
+	Page      int   `json:"page"`
+	Size      int   `json:"size"`
+	TotalItem int64 `json:"total_item"`
+	TotalPage int64 `json:"total_page"`
+	// NextCursor dipakai dengan ?cursor= untuk mengambil halaman berikutnya (keyset pagination)
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func NewPageMetadata(page int, size int, totalItem int64) *PageMetadata {
+	return &PageMetadata{
+		Page:      page,
+		Size:      size,
+		TotalItem: totalItem,
+		TotalPage: (totalItem + int64(size) - 1) / int64(size),
+	}
+}
+
+func ToPageMetadata(page *query.Page) *PageMetadata {
+	metadata := NewPageMetadata(page.Page, page.Limit, page.Total)
+	metadata.NextCursor = page.NextCursor
+	return metadata
+}
@@ -0,0 +1,80 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var errInvalidCursor = errors.New("query: invalid cursor")
+
+// cursor berisi nilai field sort dari data terakhir di halaman sebelumnya beserta sort yang dipakai,
+// sehingga cursor dari sort yang berbeda ditolak
+type cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// Cursor membuat cursor dari nilai field sort (urutan sama dengan Sorts)
+func (q *Query) Cursor(values []any) string {
+	encoded := cursor{Sort: sortSignature(q.Sorts)}
+	for _, value := range values {
+		encoded.Values = append(encoded.Values, format(value))
+	}
+
+	content, _ := json.Marshal(encoded)
+	return base64.RawURLEncoding.EncodeToString(content)
+}
+
+func (s *Schema) decodeCursor(value string, sorts []Sort) ([]any, error) {
+	content, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+
+	var decoded cursor
+	if err := json.Unmarshal(content, &decoded); err != nil {
+		return nil, errInvalidCursor
+	}
+	if decoded.Sort != sortSignature(sorts) || len(decoded.Values) != len(sorts) {
+		return nil, errInvalidCursor
+	}
+
+	after := make([]any, len(sorts))
+	for i, item := range sorts {
+		if after[i], err = s.Fields[item.Field].convert(decoded.Values[i]); err != nil {
+			return nil, errInvalidCursor
+		}
+	}
+	return after, nil
+}
+
+func sortSignature(sorts []Sort) string {
+	items := make([]string, len(sorts))
+	for i, item := range sorts {
+		items[i] = item.Field
+		if item.Desc {
+			items[i] = "-" + item.Field
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func format(value any) string {
+	switch value := value.(type) {
+	case string:
+		return value
+	case int64:
+		return strconv.FormatInt(value, 10)
+	case int:
+		return strconv.Itoa(value)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(value)
+	}
+}
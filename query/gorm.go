@@ -0,0 +1,128 @@
+package query
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Page adalah metadata satu halaman hasil query
+type Page struct {
+	Page  int
+	Limit int
+	Total int64
+	// NextCursor kosong jika tidak ada data berikutnya
+	NextCursor string
+}
+
+// Find menjalankan query untuk model T: filter, sort, lalu offset atau cursor (keyset pagination)
+func Find[T any](db *gorm.DB, q *Query) ([]T, *Page, error) {
+	// Session supaya query dengan filter bisa dipakai ulang untuk Count dan Find
+	filtered := db.Model(new(T))
+	for _, filter := range q.Filters {
+		filtered = filtered.Where(q.filterExpression(filter))
+	}
+	filtered = filtered.Session(&gorm.Session{})
+
+	var total int64
+	if err := filtered.Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	find := filtered
+	if q.After != nil {
+		find = find.Where(q.afterExpression())
+	} else {
+		find = find.Offset(q.Offset())
+	}
+	for _, item := range q.Sorts {
+		find = find.Order(clause.OrderByColumn{Column: q.column(item.Field), Desc: item.Desc})
+	}
+
+	// ambil satu data lebih untuk mengetahui masih ada halaman berikutnya
+	var items []T
+	if err := find.Limit(q.Limit + 1).Find(&items).Error; err != nil {
+		return nil, nil, err
+	}
+
+	page := q.newPage(total)
+	if len(items) > q.Limit {
+		items = items[:q.Limit]
+		values, err := q.values(db, &items[len(items)-1])
+		if err != nil {
+			return nil, nil, err
+		}
+		page.NextCursor = q.Cursor(values)
+	}
+	return items, page, nil
+}
+
+func (q *Query) newPage(total int64) *Page {
+	page := &Page{Page: q.Page, Limit: q.Limit, Total: total}
+	if q.After != nil {
+		// halaman cursor tidak punya nomor halaman
+		page.Page = 0
+	}
+	return page
+}
+
+func (q *Query) column(field string) clause.Column {
+	return clause.Column{Name: q.Schema.Fields[field].Column}
+}
+
+func (q *Query) filterExpression(filter Filter) clause.Expression {
+	column := q.column(filter.Field)
+	switch filter.Operator {
+	case Ne:
+		return clause.Neq{Column: column, Value: filter.Values[0]}
+	case Like:
+		return clause.Like{Column: column, Value: filter.Values[0]}
+	case Gt:
+		return clause.Gt{Column: column, Value: filter.Values[0]}
+	case Gte:
+		return clause.Gte{Column: column, Value: filter.Values[0]}
+	case Lt:
+		return clause.Lt{Column: column, Value: filter.Values[0]}
+	case Lte:
+		return clause.Lte{Column: column, Value: filter.Values[0]}
+	case In:
+		return clause.IN{Column: column, Values: filter.Values}
+	default:
+		return clause.Eq{Column: column, Value: filter.Values[0]}
+	}
+}
+
+// afterExpression membentuk kondisi keyset, contoh sort (-first_name, id) dengan cursor (A, 5):
+// first_name < A OR (first_name = A AND id > 5)
+func (q *Query) afterExpression() clause.Expression {
+	var or []clause.Expression
+	for i, item := range q.Sorts {
+		var and []clause.Expression
+		for j := 0; j < i; j++ {
+			and = append(and, clause.Eq{Column: q.column(q.Sorts[j].Field), Value: q.After[j]})
+		}
+		if item.Desc {
+			and = append(and, clause.Lt{Column: q.column(item.Field), Value: q.After[i]})
+		} else {
+			and = append(and, clause.Gt{Column: q.column(item.Field), Value: q.After[i]})
+		}
+		or = append(or, clause.And(and...))
+	}
+	return clause.Or(or...)
+}
+
+// values mengambil nilai field sort dari data terakhir untuk dijadikan cursor
+func (q *Query) values(db *gorm.DB, item any) ([]any, error) {
+	statement := &gorm.Statement{DB: db}
+	if err := statement.Parse(item); err != nil {
+		return nil, err
+	}
+
+	values := make([]any, len(q.Sorts))
+	for i, sort := range q.Sorts {
+		field := statement.Schema.LookUpField(q.Schema.Fields[sort.Field].Column)
+		values[i], _ = field.ValueOf(db.Statement.Context, reflect.ValueOf(item).Elem())
+	}
+	return values, nil
+}
@@ -0,0 +1,145 @@
+package query
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Row adalah nilai kolom satu data untuk query di memory (repository.MemoryStore), key-nya nama kolom
+type Row map[string]any
+
+// Slice menjalankan query yang sama dengan Find terhadap data di memory
+func Slice[T any](items []T, q *Query, row func(item T) Row) ([]T, *Page) {
+	var filtered []T
+	for _, item := range items {
+		if q.match(row(item)) {
+			filtered = append(filtered, item)
+		}
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return q.compare(row(filtered[i]), row(filtered[j])) < 0
+	})
+
+	page := q.newPage(int64(len(filtered)))
+	start := q.Offset()
+	if q.After != nil {
+		start = sort.Search(len(filtered), func(i int) bool {
+			return q.compareAfter(row(filtered[i])) > 0
+		})
+	}
+	if start > len(filtered) {
+		start = len(filtered)
+	}
+
+	result := filtered[start:]
+	if len(result) > q.Limit {
+		result = result[:q.Limit]
+		last := row(result[len(result)-1])
+		values := make([]any, len(q.Sorts))
+		for i, item := range q.Sorts {
+			values[i] = last[q.Schema.Fields[item.Field].Column]
+		}
+		page.NextCursor = q.Cursor(values)
+	}
+	return append([]T{}, result...), page
+}
+
+func (q *Query) match(row Row) bool {
+	for _, filter := range q.Filters {
+		value := row[q.Schema.Fields[filter.Field].Column]
+		switch filter.Operator {
+		case Ne:
+			if compareValue(value, filter.Values[0]) == 0 {
+				return false
+			}
+		case Like:
+			if !like(value.(string), filter.Values[0].(string)) {
+				return false
+			}
+		case Gt, Gte, Lt, Lte:
+			result := compareValue(value, filter.Values[0])
+			if (filter.Operator == Gt && result <= 0) || (filter.Operator == Gte && result < 0) ||
+				(filter.Operator == Lt && result >= 0) || (filter.Operator == Lte && result > 0) {
+				return false
+			}
+		case In:
+			found := false
+			for _, item := range filter.Values {
+				found = found || compareValue(value, item) == 0
+			}
+			if !found {
+				return false
+			}
+		default:
+			if compareValue(value, filter.Values[0]) != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (q *Query) compare(a Row, b Row) int {
+	for _, item := range q.Sorts {
+		column := q.Schema.Fields[item.Field].Column
+		if result := compareValue(a[column], b[column]); result != 0 {
+			if item.Desc {
+				return -result
+			}
+			return result
+		}
+	}
+	return 0
+}
+
+// compareAfter membandingkan row dengan posisi cursor, hasil > 0 berarti row ada setelah cursor
+func (q *Query) compareAfter(row Row) int {
+	for i, item := range q.Sorts {
+		if result := compareValue(row[q.Schema.Fields[item.Field].Column], q.After[i]); result != 0 {
+			if item.Desc {
+				return -result
+			}
+			return result
+		}
+	}
+	return 0
+}
+
+func compareValue(a any, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case int64:
+		b := b.(int64)
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	default:
+		return 0
+	}
+}
+
+// like meniru LIKE SQL (% dan _) tanpa membedakan huruf besar kecil seperti collation default MySQL
+func like(value string, pattern string) bool {
+	var builder strings.Builder
+	builder.WriteString("(?is)^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			builder.WriteString(".*")
+		case '_':
+			builder.WriteString(".")
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	builder.WriteString("$")
+	return regexp.MustCompile(builder.String()).MatchString(value)
+}
@@ -0,0 +1,255 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"belajar-golang-fiber/validation"
+)
+
+// Nama parameter query string yang dipakai untuk pagination dan sort,
+// parameter lain dianggap filter jika namanya ada di Schema.Fields
+const (
+	ParamPage   = "page"
+	ParamLimit  = "limit"
+	ParamCursor = "cursor"
+	ParamSort   = "sort"
+)
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Time
+)
+
+type Operator string
+
+const (
+	Eq   Operator = "eq"
+	Ne   Operator = "ne"
+	Like Operator = "like"
+	Gt   Operator = "gt"
+	Gte  Operator = "gte"
+	Lt   Operator = "lt"
+	Lte  Operator = "lte"
+	In   Operator = "in"
+)
+
+// operators adalah operator yang boleh dipakai untuk setiap tipe field
+var operators = map[Type][]Operator{
+	String: {Eq, Ne, Like, In},
+	Int:    {Eq, Ne, Gt, Gte, Lt, Lte, In},
+	Time:   {Eq, Ne, Gt, Gte, Lt, Lte, In},
+}
+
+// Field memetakan nama field di URL ke kolom database. Nama kolom tidak pernah diambil dari request,
+// nilai filter selalu dikirim sebagai parameter query sehingga aman dari SQL injection
+type Field struct {
+	Column     string
+	Type       Type
+	Sortable   bool
+	Filterable bool
+}
+
+// Schema adalah whitelist field untuk satu endpoint list
+type Schema struct {
+	Fields map[string]Field
+	// Key adalah field unik yang selalu ditambahkan di akhir sort supaya urutan (dan cursor) stabil
+	Key          string
+	DefaultSort  []Sort
+	DefaultLimit int
+	MaxLimit     int
+}
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+type Filter struct {
+	Field    string
+	Operator Operator
+	Values   []any
+}
+
+// Query adalah hasil parsing query string yang sudah divalidasi terhadap Schema
+type Query struct {
+	Schema  *Schema
+	Page    int
+	Limit   int
+	Sorts   []Sort
+	Filters []Filter
+	// After berisi nilai field sort dari cursor, nil jika memakai pagination offset
+	After []any
+}
+
+// Offset adalah jumlah data yang dilewati pada pagination offset
+func (q *Query) Offset() int {
+	return (q.Page - 1) * q.Limit
+}
+
+// Parse membaca ?page, ?limit, ?cursor, ?sort=-first_name,id dan filter seperti ?first_name[like]=User%.
+// Semua kesalahan dikumpulkan dalam *validation.Error
+func (s *Schema) Parse(values url.Values) (*Query, error) {
+	q := &Query{Schema: s, Page: 1, Limit: s.DefaultLimit}
+	var errs []validation.FieldError
+
+	if value := values.Get(ParamPage); value != "" {
+		page, err := strconv.Atoi(value)
+		if err != nil || page < 1 {
+			errs = append(errs, fieldError(ParamPage, "min", "1", "page must be a positive number"))
+		}
+		q.Page = page
+	}
+	if value := values.Get(ParamLimit); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > s.MaxLimit {
+			errs = append(errs, fieldError(ParamLimit, "max", strconv.Itoa(s.MaxLimit), fmt.Sprintf("limit must be between 1 and %d", s.MaxLimit)))
+		}
+		q.Limit = limit
+	}
+
+	q.Sorts = s.DefaultSort
+	if value := values.Get(ParamSort); value != "" {
+		sorts, sortErrs := s.parseSort(value)
+		q.Sorts, errs = sorts, append(errs, sortErrs...)
+	}
+	q.Sorts = s.withKey(q.Sorts)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch key {
+		case ParamPage, ParamLimit, ParamCursor, ParamSort:
+			continue
+		}
+		filters, filterErrs := s.parseFilter(key, values[key])
+		q.Filters, errs = append(q.Filters, filters...), append(errs, filterErrs...)
+	}
+
+	if value := values.Get(ParamCursor); value != "" && len(errs) == 0 {
+		after, err := s.decodeCursor(value, q.Sorts)
+		if err != nil {
+			errs = append(errs, fieldError(ParamCursor, "cursor", "", "cursor is invalid or does not match the sort"))
+		}
+		q.After = after
+	}
+
+	if len(errs) > 0 {
+		return nil, &validation.Error{Fields: errs}
+	}
+	return q, nil
+}
+
+func (s *Schema) parseSort(value string) ([]Sort, []validation.FieldError) {
+	var sorts []Sort
+	var errs []validation.FieldError
+	seen := map[string]bool{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		name := strings.TrimLeft(item, "+-")
+		field, ok := s.Fields[name]
+		if !ok || !field.Sortable {
+			errs = append(errs, fieldError(ParamSort, "oneof", strings.Join(s.names(true), " "), fmt.Sprintf("sort field %q is not allowed", name)))
+			continue
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		sorts = append(sorts, Sort{Field: name, Desc: strings.HasPrefix(item, "-")})
+	}
+	return sorts, errs
+}
+
+func (s *Schema) withKey(sorts []Sort) []Sort {
+	for _, item := range sorts {
+		if item.Field == s.Key {
+			return sorts
+		}
+	}
+	return append(append([]Sort(nil), sorts...), Sort{Field: s.Key})
+}
+
+// parseFilter membaca satu parameter filter, contoh first_name[like]=User% atau id[in]=1,2,3.
+// Parameter tanpa operator yang namanya tidak dikenal diabaikan
+func (s *Schema) parseFilter(key string, values []string) ([]Filter, []validation.FieldError) {
+	name, operator := key, Eq
+	if open := strings.Index(key, "["); open >= 0 && strings.HasSuffix(key, "]") {
+		name, operator = key[:open], Operator(key[open+1:len(key)-1])
+	} else if _, ok := s.Fields[key]; !ok {
+		return nil, nil
+	}
+
+	field, ok := s.Fields[name]
+	if !ok || !field.Filterable {
+		return nil, []validation.FieldError{fieldError(key, "oneof", strings.Join(s.names(false), " "), fmt.Sprintf("filter field %q is not allowed", name))}
+	}
+	if !allowed(field.Type, operator) {
+		return nil, []validation.FieldError{fieldError(key, "operator", string(operator), fmt.Sprintf("operator %q is not allowed for %s", operator, name))}
+	}
+
+	var filters []Filter
+	for _, value := range values {
+		raw := []string{value}
+		if operator == In {
+			raw = strings.Split(value, ",")
+		}
+
+		filter := Filter{Field: name, Operator: operator}
+		for _, item := range raw {
+			converted, err := field.convert(item)
+			if err != nil {
+				return nil, []validation.FieldError{fieldError(key, "type", "", fmt.Sprintf("%s has an invalid value %q", name, item))}
+			}
+			filter.Values = append(filter.Values, converted)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// names mengembalikan nama field yang bisa di-sort atau di-filter, untuk pesan error
+func (s *Schema) names(sortable bool) []string {
+	var names []string
+	for name, field := range s.Fields {
+		if (sortable && field.Sortable) || (!sortable && field.Filterable) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func allowed(fieldType Type, operator Operator) bool {
+	for _, item := range operators[fieldType] {
+		if item == operator {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Field) convert(value string) (any, error) {
+	switch f.Type {
+	case Int:
+		return strconv.ParseInt(value, 10, 64)
+	case Time:
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return value, nil
+	}
+}
+
+func fieldError(field string, code string, param string, message string) validation.FieldError {
+	return validation.FieldError{Field: field, Code: code, Param: param, Message: message}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/query"
+	"belajar-golang-fiber/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseUserQuery(t *testing.T, rawQuery string) *query.Query {
+	values, err := url.ParseQuery(rawQuery)
+	assert.Nil(t, err)
+	q, err := model.UserQuery.Parse(values)
+	assert.Nil(t, err)
+	return q
+}
+
+func userIds(users []entity.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+// hasil query layer harus sama dengan query manual di TestOrderLimitOffset
+func TestQueryOrderLimitOffset(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	var expected []entity.User
+	assert.Nil(t, db.Order("id asc, first_name desc").Limit(5).Offset(5).Find(&expected).Error)
+
+	users, page, err := repository.NewGormStore(db).Users().FindPage(context.Background(), parseUserQuery(t, "sort=id,-first_name&limit=5&page=2"))
+	assert.Nil(t, err)
+	assert.Equal(t, userIds(expected), userIds(users))
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, int64(14), page.Total)
+}
+
+func TestQueryFilter(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	users := repository.NewGormStore(db).Users()
+
+	result, page, err := users.FindPage(context.Background(), parseUserQuery(t, "first_name[like]=User%25&sort=-id&limit=100"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(13), page.Total)
+	assert.Equal(t, "9", result[0].ID)
+
+	result, _, err = users.FindPage(context.Background(), parseUserQuery(t, "id[in]=1,3,5&first_name[ne]=Bagus"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3", "5"}, userIds(result))
+
+	// nilai filter selalu di-bind sebagai parameter, bukan disambung ke SQL
+	result, _, err = users.FindPage(context.Background(), parseUserQuery(t, "first_name=x%27+or+%271%27%3D%271"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(result))
+}
+
+func TestQueryValidation(t *testing.T) {
+	invalid := []struct{ key, value, field string }{
+		{"sort", "password", "sort"},
+		{"sort", "id;drop table users", "sort"},
+		{"password[eq]", "rahasia", "password"},
+		{"first_name[gt]", "A", "first_name"},
+		{"first_name[regex]", "A", "first_name"},
+		{"created_at[gte]", "kemarin", "created_at"},
+		{"limit", "1000", "limit"},
+		{"page", "0", "page"},
+		{"cursor", "bukan-cursor", "cursor"},
+	}
+	for _, param := range invalid {
+		_, err := model.UserQuery.Parse(url.Values{param.key: {param.value}})
+		assert.NotNil(t, err, param.key)
+		if err != nil {
+			assert.Contains(t, err.Error(), param.field, param.key)
+		}
+	}
+
+	// cursor tidak boleh dipakai dengan sort yang berbeda
+	q := parseUserQuery(t, "sort=id")
+	cursor := q.Cursor([]any{"1"})
+	_, err := model.UserQuery.Parse(url.Values{"sort": {"-first_name"}, "cursor": {cursor}})
+	assert.NotNil(t, err)
+}
+
+func TestQueryCursor(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": repository.NewMemoryStore()}
+	memoryUsers := []entity.User{}
+	assert.Nil(t, db.Find(&memoryUsers).Error)
+	for _, user := range memoryUsers {
+		assert.Nil(t, stores["memory"].Users().Create(context.Background(), &user))
+	}
+
+	for name, store := range stores {
+		var ids []string
+		rawQuery := "sort=-first_name&limit=4"
+		for {
+			users, page, err := store.Users().FindPage(context.Background(), parseUserQuery(t, rawQuery))
+			assert.Nil(t, err, name)
+			if strings.Contains(rawQuery, "cursor=") {
+				assert.Equal(t, 0, page.Page, name)
+			}
+			ids = append(ids, userIds(users)...)
+			if page.NextCursor == "" {
+				break
+			}
+			rawQuery = "sort=-first_name&limit=4&cursor=" + page.NextCursor
+		}
+
+		assert.Equal(t, 14, len(ids), name)
+		assert.Equal(t, "9", ids[0], name)
+		assert.Equal(t, "1", ids[13], name)
+	}
+}
+
+func TestUserListPaging(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	userApp := newUserApp(repository.NewGormStore(db))
+
+	request := httptest.NewRequest("GET", "/api/users?first_name[like]=User%25&limit=5&page=2", nil)
+	response, err := userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	link := response.Header.Get("Link")
+	assert.Contains(t, link, `page=1>; rel="first"`)
+	assert.Contains(t, link, `page=1>; rel="prev"`)
+	assert.Contains(t, link, `page=3>; rel="next"`)
+	assert.Contains(t, link, `page=3>; rel="last"`)
+	assert.Contains(t, link, "first_name%5Blike%5D=User%25")
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	usersResponse := new(model.WebResponse[[]model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, usersResponse))
+	assert.Equal(t, 5, len(usersResponse.Data))
+	assert.Equal(t, 2, usersResponse.Paging.Page)
+	assert.Equal(t, int64(13), usersResponse.Paging.TotalItem)
+	assert.Equal(t, int64(3), usersResponse.Paging.TotalPage)
+
+	request = httptest.NewRequest("GET", "/api/users?cursor=x&limit=5", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 422, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/users?sort=id&limit=5", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Nil(t, json.Unmarshal(bytes, usersResponse))
+	assert.Equal(t, 5, len(usersResponse.Data))
+	assert.Contains(t, response.Header.Get("Link"), `rel="next"`)
+}
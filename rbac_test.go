@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestPermissionSet(t *testing.T) {
+	permissions := security.PermissionSet{"users:read", "files:*"}
+	assert.True(t, permissions.Has(security.PermUsersRead))
+	assert.False(t, permissions.Has(security.PermUsersWrite))
+	assert.True(t, permissions.Has(security.PermFilesWrite))
+	assert.True(t, security.PermissionSet{"*"}.Has(security.PermRolesManage))
+	assert.False(t, security.PermissionSet(nil).Has(security.PermUsersRead))
+
+	assert.True(t, security.ValidPermission("users:admin"))
+	assert.True(t, security.ValidPermission("roles:*"))
+	assert.True(t, security.ValidPermission("*"))
+	assert.False(t, security.ValidPermission("users:fly"))
+	assert.False(t, security.ValidPermission("orders:*"))
+}
+
+// newRbacApp dirakit seperti di main: permission dimuat dari role di database, header X-User menggantikan session
+func newRbacApp(store repository.Store) *fiber.App {
+	app := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	roleService := service.NewRoleService(store)
+	api := app.Group("/api", func(ctx *fiber.Ctx) error {
+		ctx.Locals(middleware.SessionUserKey, ctx.Get("X-User"))
+		return ctx.Next()
+	}, middleware.NewPermissions(roleService.Permissions))
+
+	userService := service.NewUserService(store, security.NewPasswordHasher(bcrypt.MinCost))
+	controller.NewUserController(userService, validation.New()).Route(api)
+	api.Use("/roles", middleware.Require(security.PermRolesManage))
+	controller.NewRoleController(roleService, validation.New()).Route(api)
+	return app
+}
+
+func rbacRequest(t *testing.T, app *fiber.App, userId string, method string, target string, body string) (int, string) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-User", userId)
+	if method == "PATCH" {
+		// test akses tidak menguji versi, lihat TestUserOptimisticLocking
+		request.Header.Set("If-Match", "*")
+	}
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return response.StatusCode, string(bytes)
+}
+
+func seedRoles(t *testing.T, store repository.Store) {
+	ctx := context.Background()
+	roles := []entity.Role{
+		{ID: "admin", Permissions: []entity.RolePermission{{Permission: "*"}}},
+		{ID: security.DefaultRole, Permissions: []entity.RolePermission{{Permission: security.PermUsersRead}}},
+	}
+	for i := range roles {
+		assert.Nil(t, store.Roles().Create(ctx, &roles[i]))
+	}
+
+	userService := service.NewUserService(store, security.NewPasswordHasher(bcrypt.MinCost))
+	for _, id := range []string{"admin", "bagus"} {
+		_, err := userService.Create(ctx, &model.CreateUserRequest{ID: id, Password: "rahasia123", Name: model.NameRequest{FirstName: id}})
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, store.Roles().Assign(ctx, "admin", "admin"))
+}
+
+func TestRoleBasedAccess(t *testing.T) {
+	db := testdb.New(t)
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": repository.NewMemoryStore()}
+
+	for name, store := range stores {
+		seedRoles(t, store)
+		app := newRbacApp(store)
+
+		status, _ := rbacRequest(t, app, "bagus", "GET", "/api/roles", "")
+		assert.Equal(t, 403, status, name)
+		status, _ = rbacRequest(t, app, "", "GET", "/api/users", "")
+		assert.Equal(t, 403, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "GET", "/api/users", "")
+		assert.Equal(t, 200, status, name)
+
+		// policy: tanpa users:write hanya boleh mengubah diri sendiri
+		status, _ = rbacRequest(t, app, "bagus", "PATCH", "/api/users/bagus", `{"name":{"last_name":"Wicaksono"}}`)
+		assert.Equal(t, 200, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "PATCH", "/api/users/admin", `{"name":{"last_name":"Wicaksono"}}`)
+		assert.Equal(t, 403, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "DELETE", "/api/users/bagus", "")
+		assert.Equal(t, 403, status, name)
+
+		status, body := rbacRequest(t, app, "admin", "POST", "/api/roles", `{"id":"editor","permissions":["users:write","orders:read"]}`)
+		assert.Equal(t, 422, status, name)
+		assert.Contains(t, body, "permissions[1]", name)
+		status, _ = rbacRequest(t, app, "admin", "POST", "/api/roles", `{"id":"editor","description":"Editor","permissions":["users:write","users:write"]}`)
+		assert.Equal(t, 201, status, name)
+		status, _ = rbacRequest(t, app, "admin", "POST", "/api/roles", `{"id":"editor"}`)
+		assert.Equal(t, 409, status, name)
+
+		status, _ = rbacRequest(t, app, "admin", "PUT", "/api/roles/editor/users/tidak-ada", "")
+		assert.Equal(t, 404, status, name)
+		status, _ = rbacRequest(t, app, "admin", "PUT", "/api/roles/editor/users/bagus", "")
+		assert.Equal(t, 204, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "PATCH", "/api/users/admin", `{"name":{"last_name":"Wicaksono"}}`)
+		assert.Equal(t, 200, status, name)
+
+		status, body = rbacRequest(t, app, "admin", "GET", "/api/roles?user_id=bagus", "")
+		assert.Equal(t, 200, status, name)
+		rolesResponse := new(model.WebResponse[[]model.RoleResponse])
+		assert.Nil(t, json.Unmarshal([]byte(body), rolesResponse), name)
+		assert.Equal(t, 2, len(rolesResponse.Data), name)
+		assert.Equal(t, "editor", rolesResponse.Data[0].ID, name)
+		assert.Equal(t, []string{"users:write"}, rolesResponse.Data[0].Permissions, name)
+		assert.Equal(t, security.DefaultRole, rolesResponse.Data[1].ID, name)
+
+		status, body = rbacRequest(t, app, "admin", "PUT", "/api/roles/editor", `{"description":"Editor user","permissions":["users:*"]}`)
+		assert.Equal(t, 200, status, name)
+		assert.Contains(t, body, `"permissions":["users:*"]`, name)
+		status, _ = rbacRequest(t, app, "bagus", "POST", "/api/users/admin/restore", "")
+		assert.Equal(t, 404, status, name)
+
+		status, _ = rbacRequest(t, app, "admin", "DELETE", "/api/roles/editor/users/bagus", "")
+		assert.Equal(t, 204, status, name)
+		status, _ = rbacRequest(t, app, "admin", "DELETE", "/api/roles/editor/users/bagus", "")
+		assert.Equal(t, 404, status, name)
+
+		assert.Nil(t, store.Roles().Assign(context.Background(), "bagus", "editor"))
+		status, _ = rbacRequest(t, app, "admin", "DELETE", "/api/roles/editor", "")
+		assert.Equal(t, 204, status, name)
+		status, _ = rbacRequest(t, app, "admin", "GET", "/api/roles/editor", "")
+		assert.Equal(t, 404, status, name)
+
+		permissions, err := store.Roles().Permissions(context.Background(), "bagus")
+		assert.Nil(t, err, name)
+		assert.Equal(t, []string{security.PermUsersRead}, permissions, name)
+
+		// user yang dihapus kehilangan semua permission walaupun role-nya masih tercatat
+		user, err := store.Users().FindById(context.Background(), "bagus")
+		assert.Nil(t, err, name)
+		assert.Nil(t, store.Users().Delete(context.Background(), user), name)
+		status, _ = rbacRequest(t, app, "bagus", "GET", "/api/users", "")
+		assert.Equal(t, 401, status, name)
+		permissions, err = store.Roles().Permissions(context.Background(), "bagus")
+		assert.Nil(t, err, name)
+		assert.Empty(t, permissions, name)
+		roles, err := store.Roles().FindByUserId(context.Background(), "bagus")
+		assert.Nil(t, err, name)
+		assert.Equal(t, 1, len(roles), name)
+	}
+}
+
+func TestDeletedUserSession(t *testing.T) {
+	db := testdb.New(t)
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": repository.NewMemoryStore()}
+
+	for name, store := range stores {
+		seedRoles(t, store)
+		app := newRbacApp(store)
+
+		status, _ := rbacRequest(t, app, "bagus", "GET", "/api/users", "")
+		assert.Equal(t, 200, status, name)
+		status, _ = rbacRequest(t, app, "admin", "DELETE", "/api/users/bagus", "")
+		assert.Equal(t, 204, status, name)
+
+		// session user yang dihapus ditolak di semua route, termasuk mengubah dirinya sendiri
+		status, _ = rbacRequest(t, app, "bagus", "GET", "/api/users", "")
+		assert.Equal(t, 401, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "PATCH", "/api/users/bagus", `{"name":{"last_name":"Wicaksono"}}`)
+		assert.Equal(t, 401, status, name)
+
+		// restore mengembalikan akses dengan role yang sama
+		status, _ = rbacRequest(t, app, "admin", "POST", "/api/users/bagus/restore", "")
+		assert.Equal(t, 200, status, name)
+		status, _ = rbacRequest(t, app, "bagus", "GET", "/api/users", "")
+		assert.Equal(t, 200, status, name)
+	}
+}
+
+func TestRequireAccess(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	app.Use(func(ctx *fiber.Ctx) error {
+		ctx.Locals(middleware.PermissionsKey, security.PermissionSet{security.PermFilesRead})
+		return ctx.Next()
+	}, middleware.RequireAccess(security.PermFilesRead, security.PermFilesWrite))
+	app.All("/files", func(ctx *fiber.Ctx) error {
+		return ctx.SendStatus(fiber.StatusNoContent)
+	})
+
+	for method, expected := range map[string]int{"GET": 204, "HEAD": 204, "POST": 403, "DELETE": 403} {
+		response, err := app.Test(httptest.NewRequest(method, "/files", nil))
+		assert.Nil(t, err)
+		assert.Equal(t, expected, response.StatusCode, method)
+	}
+}
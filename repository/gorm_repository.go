@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/query"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GormStore struct {
+	DB *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{DB: db}
+}
+
+func (s *GormStore) Users() UserRepository {
+	return &GormUserRepository{DB: s.DB}
+}
+
+func (s *GormStore) UserLogs() UserLogRepository {
+	return &GormUserLogRepository{DB: s.DB}
+}
+
+func (s *GormStore) Files() FileRepository {
+	return &GormFileRepository{DB: s.DB}
+}
+
+func (s *GormStore) Uploads() UploadRepository {
+	return &GormUploadRepository{DB: s.DB}
+}
+
+func (s *GormStore) Roles() RoleRepository {
+	return &GormRoleRepository{DB: s.DB}
+}
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(store Store) error) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(NewGormStore(tx))
+	})
+}
+
+// translate mengubah error GORM menjadi error repository
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicate
+	default:
+		return err
+	}
+}
+
+type GormUserRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormUserRepository) Create(ctx context.Context, user *entity.User) error {
+	user.Version = 1
+	return translate(r.DB.WithContext(ctx).Create(user).Error)
+}
+
+func (r *GormUserRepository) FindById(ctx context.Context, id string) (*entity.User, error) {
+	user := new(entity.User)
+	if err := r.DB.WithContext(ctx).Take(user, "id = ?", id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return user, nil
+}
+
+func (r *GormUserRepository) FindPage(ctx context.Context, q *query.Query) ([]entity.User, *query.Page, error) {
+	users, page, err := query.Find[entity.User](r.DB.WithContext(ctx), q)
+	return users, page, translate(err)
+}
+
+// Search memakai index FULLTEXT users_name_fulltext di MySQL: setiap kata dicari sebagai awalan (boolean mode)
+// ditambah awalan dua huruf (searchPrefix) dengan bobot lebih kecil supaya kata yang salah ketik tetap
+// menjadi kandidat, diurutkan dari relevansi tertinggi. Kandidat hanya diambil lewat MATCH agar index tetap
+// dipakai, toleransi salah ketik dihitung ulang oleh search.Index di service. Database lain (SQLite untuk test)
+// tidak punya FULLTEXT sehingga kandidat disaring dengan LIKE awalan kata, yang mengandung kata utuh lebih dulu
+func (r *GormUserRepository) Search(ctx context.Context, terms []string, limit int) ([]entity.User, error) {
+	db := r.DB.WithContext(ctx)
+	var users []entity.User
+	if db.Dialector.Name() != "mysql" {
+		return users, translate(r.searchLike(db, terms, limit).Find(&users).Error)
+	}
+
+	// terms hasil search.Tokenize hanya berisi huruf dan angka, aman dari operator boolean mode
+	var against []string
+	for _, term := range terms {
+		against = append(against, term+"*")
+		if prefix := searchPrefix(term); prefix != term {
+			against = append(against, "<"+prefix+"*")
+		}
+	}
+	match := clause.Expr{
+		SQL:  "MATCH (first_name, middle_name, last_name) AGAINST (? IN BOOLEAN MODE)",
+		Vars: []any{strings.Join(against, " ")},
+	}
+
+	err := db.Where(match).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "? DESC", Vars: []any{match}}}).
+		Limit(limit).
+		Find(&users).Error
+	return users, translate(err)
+}
+
+// searchLike menyaring kandidat dengan LIKE, terms hanya berisi huruf dan angka sehingga tidak ada wildcard di dalamnya
+func (r *GormUserRepository) searchLike(db *gorm.DB, terms []string, limit int) *gorm.DB {
+	var prefixes, exact []clause.Expression
+	for _, term := range terms {
+		for _, column := range searchNameColumns {
+			lower := clause.Expr{SQL: "LOWER(?)", Vars: []any{clause.Column{Name: column}}}
+			prefixes = append(prefixes, clause.Expr{SQL: "? LIKE ?", Vars: []any{lower, "%" + searchPrefix(term) + "%"}})
+			exact = append(exact, clause.Expr{SQL: "? LIKE ?", Vars: []any{lower, "%" + term + "%"}})
+		}
+	}
+	rank := clause.Expr{SQL: "CASE WHEN ? THEN 0 ELSE 1 END, ?", Vars: []any{clause.Or(exact...), clause.Column{Name: "id"}}}
+	return db.Where(clause.Or(prefixes...)).
+		Order(clause.OrderBy{Expression: rank}).
+		Limit(limit)
+}
+
+func (r *GormUserRepository) Save(ctx context.Context, user *entity.User) error {
+	version := user.Version
+	user.Version++
+	// Select("*") supaya field yang dikosongkan ikut tersimpan, WHERE version memastikan tidak ada perubahan lain di antaranya
+	result := r.DB.WithContext(ctx).Model(user).
+		Where("version = ?", version).
+		Select("*").Omit("created_at", "deleted_at").
+		Updates(user)
+	if result.Error == nil && result.RowsAffected > 0 {
+		return nil
+	}
+
+	user.Version = version
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if _, err := r.FindById(ctx, user.ID); err != nil {
+		return err
+	}
+	return ErrConflict
+}
+
+func (r *GormUserRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	// UpdateColumn tidak mengubah updated_at, version juga tetap supaya ETag client tidak berubah
+	result := r.DB.WithContext(ctx).Model(&entity.User{}).Where("id = ?", id).UpdateColumn("password", password)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormUserRepository) Delete(ctx context.Context, user *entity.User) error {
+	result := r.DB.WithContext(ctx).Delete(user)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormUserRepository) Unscoped() UserRepository {
+	return &GormUserRepository{DB: r.DB.Unscoped()}
+}
+
+func (r *GormUserRepository) Restore(ctx context.Context, id string) error {
+	result := r.DB.WithContext(ctx).Unscoped().Model(&entity.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormUserRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.DB.WithContext(ctx).Unscoped().Model(&entity.User{}).
+		Where("deleted_at < ?", before).
+		Order("deleted_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, translate(err)
+}
+
+type GormUserLogRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormUserLogRepository) Create(ctx context.Context, log *entity.UserLogs) error {
+	return translate(r.DB.WithContext(ctx).Create(log).Error)
+}
+
+func (r *GormUserLogRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	return translate(r.DB.WithContext(ctx).Where("user_id = ?", userId).Delete(&entity.UserLogs{}).Error)
+}
+
+func (r *GormUserLogRepository) FindByUserId(ctx context.Context, userId string, page int, size int) ([]entity.UserLogs, int64, error) {
+	// Session supaya query bisa dipakai ulang untuk Count dan Find
+	query := r.DB.WithContext(ctx).Model(&entity.UserLogs{}).Where("user_id = ?", userId).Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []entity.UserLogs
+	err := query.Order("id desc").Limit(size).Offset((page - 1) * size).Find(&logs).Error
+	return logs, total, err
+}
+
+type GormFileRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormFileRepository) Create(ctx context.Context, file *entity.File) error {
+	return translate(r.DB.WithContext(ctx).Create(file).Error)
+}
+
+func (r *GormFileRepository) FindById(ctx context.Context, id string) (*entity.File, error) {
+	file := new(entity.File)
+	if err := r.DB.WithContext(ctx).Take(file, "id = ?", id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return file, nil
+}
+
+type GormUploadRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormUploadRepository) Create(ctx context.Context, upload *entity.Upload) error {
+	return translate(r.DB.WithContext(ctx).Create(upload).Error)
+}
+
+func (r *GormUploadRepository) FindById(ctx context.Context, id string) (*entity.Upload, error) {
+	upload := new(entity.Upload)
+	if err := r.DB.WithContext(ctx).Take(upload, "id = ?", id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return upload, nil
+}
+
+func (r *GormUploadRepository) UpdateOffset(ctx context.Context, id string, offset int64) error {
+	result := r.DB.WithContext(ctx).Model(&entity.Upload{}).Where("id = ?", id).Update("upload_offset", offset)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormUploadRepository) Delete(ctx context.Context, id string) error {
+	return translate(r.DB.WithContext(ctx).Delete(&entity.Upload{}, "id = ?", id).Error)
+}
+
+func (r *GormUploadRepository) FindUpdatedBefore(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	var ids []string
+	err := r.DB.WithContext(ctx).Model(&entity.Upload{}).
+		Where("updated_at < ?", before).
+		Order("updated_at").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, translate(err)
+}
+
+type GormRoleRepository struct {
+	DB *gorm.DB
+}
+
+func (r *GormRoleRepository) Create(ctx context.Context, role *entity.Role) error {
+	return translate(r.DB.WithContext(ctx).Create(role).Error)
+}
+
+func (r *GormRoleRepository) FindById(ctx context.Context, id string) (*entity.Role, error) {
+	role := new(entity.Role)
+	if err := r.DB.WithContext(ctx).Preload("Permissions").Take(role, "id = ?", id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return role, nil
+}
+
+func (r *GormRoleRepository) FindAll(ctx context.Context) ([]entity.Role, error) {
+	var roles []entity.Role
+	err := r.DB.WithContext(ctx).Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, translate(err)
+}
+
+func (r *GormRoleRepository) Save(ctx context.Context, role *entity.Role) error {
+	db := r.DB.WithContext(ctx)
+	result := db.Model(role).Update("description", role.Description)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	if err := db.Where("role_id = ?", role.ID).Delete(&entity.RolePermission{}).Error; err != nil {
+		return translate(err)
+	}
+	if len(role.Permissions) == 0 {
+		return nil
+	}
+	for i := range role.Permissions {
+		role.Permissions[i].RoleId = role.ID
+	}
+	return translate(db.Create(&role.Permissions).Error)
+}
+
+// Delete sebaiknya dipanggil di dalam Transaction, data turunan dihapus lebih dulu (foreign key)
+func (r *GormRoleRepository) Delete(ctx context.Context, id string) error {
+	db := r.DB.WithContext(ctx)
+	if err := db.Where("role_id = ?", id).Delete(&entity.UserRole{}).Error; err != nil {
+		return translate(err)
+	}
+	if err := db.Where("role_id = ?", id).Delete(&entity.RolePermission{}).Error; err != nil {
+		return translate(err)
+	}
+
+	result := db.Delete(&entity.Role{}, "id = ?", id)
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRoleRepository) Assign(ctx context.Context, userId string, roleId string) error {
+	userRole := &entity.UserRole{UserId: userId, RoleId: roleId}
+	return translate(r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(userRole).Error)
+}
+
+func (r *GormRoleRepository) Revoke(ctx context.Context, userId string, roleId string) error {
+	result := r.DB.WithContext(ctx).Where("user_id = ? AND role_id = ?", userId, roleId).Delete(&entity.UserRole{})
+	if result.Error != nil {
+		return translate(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GormRoleRepository) RevokeAll(ctx context.Context, userId string) error {
+	return translate(r.DB.WithContext(ctx).Where("user_id = ?", userId).Delete(&entity.UserRole{}).Error)
+}
+
+func (r *GormRoleRepository) userRoleIds(db *gorm.DB, userId string) *gorm.DB {
+	return db.Model(&entity.UserRole{}).Select("role_id").Where("user_id = ?", userId)
+}
+
+// activeUserRoleIds sama seperti userRoleIds tetapi kosong untuk user yang sudah dihapus (soft delete),
+// role-nya tetap tersimpan sehingga kembali aktif saat user di-restore
+func (r *GormRoleRepository) activeUserRoleIds(db *gorm.DB, userId string) *gorm.DB {
+	return db.Model(&entity.UserRole{}).Select("user_roles.role_id").
+		Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Where("user_roles.user_id = ?", userId)
+}
+
+func (r *GormRoleRepository) FindByUserId(ctx context.Context, userId string) ([]entity.Role, error) {
+	db := r.DB.WithContext(ctx)
+	var roles []entity.Role
+	err := db.Preload("Permissions").Where("id IN (?)", r.userRoleIds(db, userId)).Order("id").Find(&roles).Error
+	return roles, translate(err)
+}
+
+func (r *GormRoleRepository) Permissions(ctx context.Context, userId string) ([]string, error) {
+	db := r.DB.WithContext(ctx)
+	var permissions []string
+	err := db.Model(&entity.RolePermission{}).
+		Distinct("permission").
+		Where("role_id IN (?)", r.activeUserRoleIds(db, userId)).
+		Order("permission").
+		Pluck("permission", &permissions).Error
+	return permissions, translate(err)
+}
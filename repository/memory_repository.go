@@ -0,0 +1,604 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/query"
+
+	"gorm.io/gorm"
+)
+
+// MemoryStore menyimpan data di memory, dipakai untuk unit test tanpa database.
+// Transaction menyimpan salinan data dan mengembalikannya jika fn mengembalikan error
+type MemoryStore struct {
+	mutex *sync.Mutex
+	data  *memoryData
+	inTx  bool
+}
+
+type memoryData struct {
+	users     map[string]entity.User
+	logs      []entity.UserLogs
+	nextLogId int
+	files     map[string]entity.File
+	uploads   map[string]entity.Upload
+	roles     map[string]entity.Role
+	userRoles []entity.UserRole
+}
+
+func (d *memoryData) clone() *memoryData {
+	users := make(map[string]entity.User, len(d.users))
+	for id, user := range d.users {
+		users[id] = user
+	}
+	files := make(map[string]entity.File, len(d.files))
+	for id, file := range d.files {
+		files[id] = file
+	}
+	uploads := make(map[string]entity.Upload, len(d.uploads))
+	for id, upload := range d.uploads {
+		uploads[id] = upload
+	}
+	roles := make(map[string]entity.Role, len(d.roles))
+	for id, role := range d.roles {
+		role.Permissions = append([]entity.RolePermission(nil), role.Permissions...)
+		roles[id] = role
+	}
+	return &memoryData{
+		users:     users,
+		logs:      append([]entity.UserLogs(nil), d.logs...),
+		nextLogId: d.nextLogId,
+		files:     files,
+		uploads:   uploads,
+		roles:     roles,
+		userRoles: append([]entity.UserRole(nil), d.userRoles...),
+	}
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mutex: &sync.Mutex{},
+		data: &memoryData{
+			users:     map[string]entity.User{},
+			nextLogId: 1,
+			files:     map[string]entity.File{},
+			uploads:   map[string]entity.Upload{},
+			roles:     map[string]entity.Role{},
+		},
+	}
+}
+
+func (s *MemoryStore) Users() UserRepository {
+	return &MemoryUserRepository{store: s}
+}
+
+func (s *MemoryStore) UserLogs() UserLogRepository {
+	return &MemoryUserLogRepository{store: s}
+}
+
+func (s *MemoryStore) Files() FileRepository {
+	return &MemoryFileRepository{store: s}
+}
+
+func (s *MemoryStore) Uploads() UploadRepository {
+	return &MemoryUploadRepository{store: s}
+}
+
+func (s *MemoryStore) Roles() RoleRepository {
+	return &MemoryRoleRepository{store: s}
+}
+
+func (s *MemoryStore) Transaction(ctx context.Context, fn func(store Store) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	snapshot := s.data.clone()
+	err := fn(&MemoryStore{mutex: s.mutex, data: s.data, inTx: true})
+	if err != nil {
+		*s.data = *snapshot
+	}
+	return err
+}
+
+// lock tidak mengunci ulang jika sedang di dalam Transaction (mutex sudah dipegang oleh transaksi)
+func (s *MemoryStore) lock() func() {
+	if s.inTx {
+		return func() {}
+	}
+	s.mutex.Lock()
+	return s.mutex.Unlock
+}
+
+type MemoryUserRepository struct {
+	store    *MemoryStore
+	unscoped bool
+}
+
+// find mengambil user yang belum dihapus, atau semua user jika repository Unscoped
+func (r *MemoryUserRepository) find(id string) (entity.User, bool) {
+	user, ok := r.store.data.users[id]
+	if !ok || (user.DeletedAt.Valid && !r.unscoped) {
+		return entity.User{}, false
+	}
+	return user, true
+}
+
+func (r *MemoryUserRepository) all() []entity.User {
+	users := make([]entity.User, 0, len(r.store.data.users))
+	for _, user := range r.store.data.users {
+		if !user.DeletedAt.Valid || r.unscoped {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+func (r *MemoryUserRepository) Create(ctx context.Context, user *entity.User) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.users[user.ID]; ok {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	user.CreatedAt, user.UpdatedAt = now, now
+	user.Version = 1
+	r.store.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindById(ctx context.Context, id string) (*entity.User, error) {
+	defer r.store.lock()()
+
+	user, ok := r.find(id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) FindPage(ctx context.Context, q *query.Query) ([]entity.User, *query.Page, error) {
+	defer r.store.lock()()
+
+	result, page := query.Slice(r.all(), q, userRow)
+	return result, page, nil
+}
+
+// Search menyaring kandidat dengan awalan kata seperti fallback LIKE di GormUserRepository, MemoryStore tidak punya index
+func (r *MemoryUserRepository) Search(ctx context.Context, terms []string, limit int) ([]entity.User, error) {
+	defer r.store.lock()()
+
+	contains := func(user entity.User, part func(term string) string) bool {
+		for _, term := range terms {
+			for _, name := range []string{user.Name.FirstName, user.Name.MiddleName, user.Name.LastName} {
+				if strings.Contains(strings.ToLower(name), part(term)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	exact := func(term string) string { return term }
+
+	var users []entity.User
+	for _, user := range r.all() {
+		if contains(user, searchPrefix) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if iExact, jExact := contains(users[i], exact), contains(users[j], exact); iExact != jExact {
+			return iExact
+		}
+		return users[i].ID < users[j].ID
+	})
+	if len(users) > limit {
+		users = users[:limit]
+	}
+	return users, nil
+}
+
+func userRow(user entity.User) query.Row {
+	return query.Row{
+		"id":          user.ID,
+		"first_name":  user.Name.FirstName,
+		"middle_name": user.Name.MiddleName,
+		"last_name":   user.Name.LastName,
+		"created_at":  user.CreatedAt,
+		"updated_at":  user.UpdatedAt,
+	}
+}
+
+func (r *MemoryUserRepository) Save(ctx context.Context, user *entity.User) error {
+	defer r.store.lock()()
+
+	old, ok := r.find(user.ID)
+	if !ok {
+		return ErrNotFound
+	}
+	if old.Version != user.Version {
+		return ErrConflict
+	}
+	user.CreatedAt = old.CreatedAt
+	user.DeletedAt = old.DeletedAt
+	user.UpdatedAt = time.Now()
+	user.Version++
+	r.store.data.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdatePassword(ctx context.Context, id string, password string) error {
+	defer r.store.lock()()
+
+	user, ok := r.find(id)
+	if !ok {
+		return ErrNotFound
+	}
+	user.Password = password
+	r.store.data.users[id] = user
+	return nil
+}
+
+func (r *MemoryUserRepository) Delete(ctx context.Context, user *entity.User) error {
+	defer r.store.lock()()
+
+	deleted, ok := r.find(user.ID)
+	if !ok {
+		return ErrNotFound
+	}
+	if r.unscoped {
+		delete(r.store.data.users, user.ID)
+		return nil
+	}
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.data.users[user.ID] = deleted
+	*user = deleted
+	return nil
+}
+
+func (r *MemoryUserRepository) Unscoped() UserRepository {
+	return &MemoryUserRepository{store: r.store, unscoped: true}
+}
+
+func (r *MemoryUserRepository) Restore(ctx context.Context, id string) error {
+	defer r.store.lock()()
+
+	user, ok := r.store.data.users[id]
+	if !ok || !user.DeletedAt.Valid {
+		return ErrNotFound
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	r.store.data.users[id] = user
+	return nil
+}
+
+func (r *MemoryUserRepository) FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	defer r.store.lock()()
+
+	var users []entity.User
+	for _, user := range r.store.data.users {
+		if user.DeletedAt.Valid && user.DeletedAt.Time.Before(before) {
+			users = append(users, user)
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].DeletedAt.Time.Before(users[j].DeletedAt.Time)
+	})
+
+	ids := make([]string, 0, min(len(users), limit))
+	for i := 0; i < len(users) && i < limit; i++ {
+		ids = append(ids, users[i].ID)
+	}
+	return ids, nil
+}
+
+type MemoryUserLogRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryUserLogRepository) Create(ctx context.Context, log *entity.UserLogs) error {
+	defer r.store.lock()()
+
+	log.ID = strconv.Itoa(r.store.data.nextLogId)
+	r.store.data.nextLogId++
+	now := time.Now()
+	log.CreatedAt, log.UpdatedAt = now, now
+	r.store.data.logs = append(r.store.data.logs, *log)
+	return nil
+}
+
+func (r *MemoryUserLogRepository) DeleteByUserId(ctx context.Context, userId string) error {
+	defer r.store.lock()()
+
+	logs := r.store.data.logs[:0]
+	for _, log := range r.store.data.logs {
+		if log.UserId != userId {
+			logs = append(logs, log)
+		}
+	}
+	r.store.data.logs = logs
+	return nil
+}
+
+func (r *MemoryUserLogRepository) FindByUserId(ctx context.Context, userId string, page int, size int) ([]entity.UserLogs, int64, error) {
+	defer r.store.lock()()
+
+	var logs []entity.UserLogs
+	for i := len(r.store.data.logs) - 1; i >= 0; i-- {
+		if r.store.data.logs[i].UserId == userId {
+			logs = append(logs, r.store.data.logs[i])
+		}
+	}
+
+	total := int64(len(logs))
+	start := (page - 1) * size
+	if start >= len(logs) {
+		return []entity.UserLogs{}, total, nil
+	}
+	end := start + size
+	if end > len(logs) {
+		end = len(logs)
+	}
+	return logs[start:end], total, nil
+}
+
+type MemoryFileRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryFileRepository) Create(ctx context.Context, file *entity.File) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.files[file.ID]; ok {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	file.CreatedAt, file.UpdatedAt = now, now
+	r.store.data.files[file.ID] = *file
+	return nil
+}
+
+func (r *MemoryFileRepository) FindById(ctx context.Context, id string) (*entity.File, error) {
+	defer r.store.lock()()
+
+	file, ok := r.store.data.files[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &file, nil
+}
+
+type MemoryUploadRepository struct {
+	store *MemoryStore
+}
+
+func (r *MemoryUploadRepository) Create(ctx context.Context, upload *entity.Upload) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.uploads[upload.ID]; ok {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	upload.CreatedAt, upload.UpdatedAt = now, now
+	r.store.data.uploads[upload.ID] = *upload
+	return nil
+}
+
+func (r *MemoryUploadRepository) FindById(ctx context.Context, id string) (*entity.Upload, error) {
+	defer r.store.lock()()
+
+	upload, ok := r.store.data.uploads[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &upload, nil
+}
+
+func (r *MemoryUploadRepository) UpdateOffset(ctx context.Context, id string, offset int64) error {
+	defer r.store.lock()()
+
+	upload, ok := r.store.data.uploads[id]
+	if !ok {
+		return ErrNotFound
+	}
+	upload.Offset = offset
+	upload.UpdatedAt = time.Now()
+	r.store.data.uploads[id] = upload
+	return nil
+}
+
+func (r *MemoryUploadRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock()()
+
+	delete(r.store.data.uploads, id)
+	return nil
+}
+
+func (r *MemoryUploadRepository) FindUpdatedBefore(ctx context.Context, before time.Time, limit int) ([]string, error) {
+	defer r.store.lock()()
+
+	var uploads []entity.Upload
+	for _, upload := range r.store.data.uploads {
+		if upload.UpdatedAt.Before(before) {
+			uploads = append(uploads, upload)
+		}
+	}
+	sort.Slice(uploads, func(i, j int) bool {
+		return uploads[i].UpdatedAt.Before(uploads[j].UpdatedAt)
+	})
+
+	ids := make([]string, 0, min(len(uploads), limit))
+	for i := 0; i < len(uploads) && i < limit; i++ {
+		ids = append(ids, uploads[i].ID)
+	}
+	return ids, nil
+}
+
+type MemoryRoleRepository struct {
+	store *MemoryStore
+}
+
+// copyRole menyalin slice Permissions supaya data di store tidak ikut berubah dari luar
+func copyRole(role entity.Role) entity.Role {
+	role.Permissions = append([]entity.RolePermission(nil), role.Permissions...)
+	return role
+}
+
+func (r *MemoryRoleRepository) Create(ctx context.Context, role *entity.Role) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.roles[role.ID]; ok {
+		return ErrDuplicate
+	}
+	now := time.Now()
+	role.CreatedAt, role.UpdatedAt = now, now
+	for i := range role.Permissions {
+		role.Permissions[i].RoleId = role.ID
+	}
+	r.store.data.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *MemoryRoleRepository) FindById(ctx context.Context, id string) (*entity.Role, error) {
+	defer r.store.lock()()
+
+	role, ok := r.store.data.roles[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	role = copyRole(role)
+	return &role, nil
+}
+
+func (r *MemoryRoleRepository) FindAll(ctx context.Context) ([]entity.Role, error) {
+	defer r.store.lock()()
+
+	return r.find(func(role entity.Role) bool { return true }), nil
+}
+
+func (r *MemoryRoleRepository) find(match func(role entity.Role) bool) []entity.Role {
+	roles := []entity.Role{}
+	for _, role := range r.store.data.roles {
+		if match(role) {
+			roles = append(roles, copyRole(role))
+		}
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		return roles[i].ID < roles[j].ID
+	})
+	return roles
+}
+
+func (r *MemoryRoleRepository) Save(ctx context.Context, role *entity.Role) error {
+	defer r.store.lock()()
+
+	old, ok := r.store.data.roles[role.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	role.CreatedAt = old.CreatedAt
+	role.UpdatedAt = time.Now()
+	for i := range role.Permissions {
+		role.Permissions[i].RoleId = role.ID
+	}
+	r.store.data.roles[role.ID] = copyRole(*role)
+	return nil
+}
+
+func (r *MemoryRoleRepository) Delete(ctx context.Context, id string) error {
+	defer r.store.lock()()
+
+	if _, ok := r.store.data.roles[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.store.data.roles, id)
+	r.removeUserRoles(func(userRole entity.UserRole) bool { return userRole.RoleId == id })
+	return nil
+}
+
+func (r *MemoryRoleRepository) removeUserRoles(match func(userRole entity.UserRole) bool) int {
+	kept := r.store.data.userRoles[:0]
+	for _, userRole := range r.store.data.userRoles {
+		if !match(userRole) {
+			kept = append(kept, userRole)
+		}
+	}
+	removed := len(r.store.data.userRoles) - len(kept)
+	r.store.data.userRoles = kept
+	return removed
+}
+
+func (r *MemoryRoleRepository) hasRole(userId string, roleId string) bool {
+	for _, userRole := range r.store.data.userRoles {
+		if userRole.UserId == userId && userRole.RoleId == roleId {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *MemoryRoleRepository) Assign(ctx context.Context, userId string, roleId string) error {
+	defer r.store.lock()()
+
+	// string dari ctx.Params fiber memakai ulang buffer request, harus disalin sebelum disimpan
+	if !r.hasRole(userId, roleId) {
+		userRole := entity.UserRole{UserId: strings.Clone(userId), RoleId: strings.Clone(roleId), CreatedAt: time.Now()}
+		r.store.data.userRoles = append(r.store.data.userRoles, userRole)
+	}
+	return nil
+}
+
+func (r *MemoryRoleRepository) Revoke(ctx context.Context, userId string, roleId string) error {
+	defer r.store.lock()()
+
+	removed := r.removeUserRoles(func(userRole entity.UserRole) bool {
+		return userRole.UserId == userId && userRole.RoleId == roleId
+	})
+	if removed == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *MemoryRoleRepository) RevokeAll(ctx context.Context, userId string) error {
+	defer r.store.lock()()
+
+	r.removeUserRoles(func(userRole entity.UserRole) bool { return userRole.UserId == userId })
+	return nil
+}
+
+func (r *MemoryRoleRepository) FindByUserId(ctx context.Context, userId string) ([]entity.Role, error) {
+	defer r.store.lock()()
+
+	return r.find(func(role entity.Role) bool { return r.hasRole(userId, role.ID) }), nil
+}
+
+func (r *MemoryRoleRepository) Permissions(ctx context.Context, userId string) ([]string, error) {
+	defer r.store.lock()()
+
+	seen := map[string]bool{}
+	permissions := []string{}
+	// user yang sudah dihapus (soft delete) tidak memiliki permission sampai di-restore
+	if user, ok := r.store.data.users[userId]; !ok || user.DeletedAt.Valid {
+		return permissions, nil
+	}
+	for _, role := range r.find(func(role entity.Role) bool { return r.hasRole(userId, role.ID) }) {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Permission] {
+				seen[permission.Permission] = true
+				permissions = append(permissions, permission.Permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions, nil
+}
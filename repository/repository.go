@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/query"
+)
+
+var (
+	ErrNotFound  = errors.New("record not found")
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrConflict dikembalikan Save jika data sudah diubah oleh proses lain sejak dibaca (versi berbeda)
+	ErrConflict = errors.New("record was modified concurrently")
+)
+
+// searchNameColumns adalah kolom nama user yang dicari oleh UserRepository.Search
+var searchNameColumns = []string{"first_name", "middle_name", "last_name"}
+
+// searchPrefix mengambil dua huruf pertama kata untuk mencari kandidat, dengan MATCH maupun LIKE.
+// Salah ketik setelah huruf kedua tetap ditemukan, toleransi penuh dihitung oleh search.Index
+func searchPrefix(term string) string {
+	runes := []rune(term)
+	if len(runes) > 2 {
+		runes = runes[:2]
+	}
+	return string(runes)
+}
+
+type UserRepository interface {
+	Create(ctx context.Context, user *entity.User) error
+	FindById(ctx context.Context, id string) (*entity.User, error)
+	// FindPage mengambil user sesuai filter, sort dan pagination dari query
+	FindPage(ctx context.Context, q *query.Query) ([]entity.User, *query.Page, error)
+	// Search mengambil paling banyak limit kandidat user untuk kata-kata pencarian, peringkat akhir dihitung di service.
+	// Kandidat selalu disaring dengan index FULLTEXT atau awalan kata (searchPrefix) supaya tidak membaca seluruh tabel
+	Search(ctx context.Context, terms []string, limit int) ([]entity.User, error)
+	// Save menyimpan perubahan user dengan versi user.Version lalu menaikkan versinya,
+	// ErrConflict jika versi di database sudah berbeda
+	Save(ctx context.Context, user *entity.User) error
+	// UpdatePassword mengganti hash password untuk rehash saat login. Tidak mengubah version dan updated_at
+	// karena data yang terlihat client tidak berubah, perubahan password oleh user memakai Save
+	UpdatePassword(ctx context.Context, id string, password string) error
+	// Delete hanya soft delete (mengisi deleted_at), kecuali dari repository Unscoped
+	Delete(ctx context.Context, user *entity.User) error
+	// Unscoped mengembalikan repository yang juga melihat user yang sudah dihapus
+	Unscoped() UserRepository
+	// Restore mengosongkan deleted_at, ErrNotFound jika user tidak ada atau tidak sedang dihapus
+	Restore(ctx context.Context, id string) error
+	// FindDeletedBefore mengambil ID user yang dihapus sebelum waktu tertentu, untuk dihapus permanen
+	FindDeletedBefore(ctx context.Context, before time.Time, limit int) ([]string, error)
+}
+
+type UserLogRepository interface {
+	Create(ctx context.Context, log *entity.UserLogs) error
+	DeleteByUserId(ctx context.Context, userId string) error
+	// FindByUserId mengambil log terbaru lebih dulu beserta jumlah total log milik user
+	FindByUserId(ctx context.Context, userId string, page int, size int) ([]entity.UserLogs, int64, error)
+}
+
+type FileRepository interface {
+	Create(ctx context.Context, file *entity.File) error
+	FindById(ctx context.Context, id string) (*entity.File, error)
+}
+
+type UploadRepository interface {
+	Create(ctx context.Context, upload *entity.Upload) error
+	FindById(ctx context.Context, id string) (*entity.Upload, error)
+	// UpdateOffset juga memperbarui UpdatedAt, dasar perhitungan kedaluwarsa sesi upload
+	UpdateOffset(ctx context.Context, id string, offset int64) error
+	Delete(ctx context.Context, id string) error
+	// FindUpdatedBefore mengambil ID sesi upload yang terakhir diubah sebelum waktu tertentu, paling lama lebih dulu
+	FindUpdatedBefore(ctx context.Context, before time.Time, limit int) ([]string, error)
+}
+
+type RoleRepository interface {
+	// Create menyimpan role beserta Permissions-nya
+	Create(ctx context.Context, role *entity.Role) error
+	FindById(ctx context.Context, id string) (*entity.Role, error)
+	FindAll(ctx context.Context) ([]entity.Role, error)
+	// Save mengganti description dan seluruh permission role
+	Save(ctx context.Context, role *entity.Role) error
+	// Delete menghapus role beserta permission dan pemberian role ke user
+	Delete(ctx context.Context, id string) error
+	// Assign memberikan role ke user, tidak error jika user sudah memiliki role tersebut
+	Assign(ctx context.Context, userId string, roleId string) error
+	Revoke(ctx context.Context, userId string, roleId string) error
+	RevokeAll(ctx context.Context, userId string) error
+	FindByUserId(ctx context.Context, userId string) ([]entity.Role, error)
+	// Permissions mengambil gabungan permission dari semua role milik user, kosong jika user sudah dihapus
+	Permissions(ctx context.Context, userId string) ([]string, error)
+}
+
+// Store memberikan akses ke semua repository. Repository yang didapat dari store di dalam
+// Transaction hanya berlaku selama transaksi tersebut
+type Store interface {
+	Users() UserRepository
+	UserLogs() UserLogRepository
+	Files() FileRepository
+	Uploads() UploadRepository
+	Roles() RoleRepository
+	Transaction(ctx context.Context, fn func(store Store) error) error
+}
@@ -0,0 +1,213 @@
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// skor untuk setiap jenis kecocokan antara kata yang dicari dan kata di dokumen
+const (
+	scoreExact  = 1.0
+	scorePrefix = 0.75
+	scoreFuzzy  = 0.5
+)
+
+// Tokenize memecah teks menjadi kata-kata huruf kecil, selain huruf dan angka dianggap pemisah
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Index adalah inverted index sederhana di memory: kata => dokumen => field yang mengandung kata tersebut
+type Index struct {
+	terms map[string]map[string]map[string]bool
+}
+
+func NewIndex() *Index {
+	return &Index{terms: map[string]map[string]map[string]bool{}}
+}
+
+// Add menambahkan dokumen, fields berisi nama field => teks
+func (i *Index) Add(id string, fields map[string]string) {
+	for field, text := range fields {
+		for _, term := range Tokenize(text) {
+			docs, ok := i.terms[term]
+			if !ok {
+				docs = map[string]map[string]bool{}
+				i.terms[term] = docs
+			}
+			if docs[id] == nil {
+				docs[id] = map[string]bool{}
+			}
+			docs[id][field] = true
+		}
+	}
+}
+
+// Hit adalah satu dokumen hasil pencarian
+type Hit struct {
+	ID    string
+	Score float64
+	// Terms berisi kata di dokumen yang cocok per field, dipakai untuk Highlight
+	Terms map[string][]string
+}
+
+// Search mencari dokumen yang cocok dengan kata-kata di text secara persis, awalan (prefix)
+// atau dengan salah ketik. Skor setiap kata dijumlahkan, hasil diurutkan dari skor tertinggi
+func (i *Index) Search(text string, limit int) []Hit {
+	hits := map[string]*Hit{}
+	for _, word := range unique(Tokenize(text)) {
+		best := map[string]float64{}
+		for term, docs := range i.terms {
+			score := match(word, term)
+			if score == 0 {
+				continue
+			}
+			for id, fields := range docs {
+				hit, ok := hits[id]
+				if !ok {
+					hit = &Hit{ID: id, Terms: map[string][]string{}}
+					hits[id] = hit
+				}
+				for field := range fields {
+					hit.Terms[field] = append(hit.Terms[field], term)
+				}
+				best[id] = max(best[id], score)
+			}
+		}
+		for id, score := range best {
+			hits[id].Score += score
+		}
+	}
+
+	result := make([]Hit, 0, len(hits))
+	for _, hit := range hits {
+		result = append(result, *hit)
+	}
+	sort.Slice(result, func(a, b int) bool {
+		if result[a].Score != result[b].Score {
+			return result[a].Score > result[b].Score
+		}
+		return result[a].ID < result[b].ID
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
+
+func unique(words []string) []string {
+	seen := map[string]bool{}
+	result := words[:0]
+	for _, word := range words {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+// match menghitung skor kecocokan word (dari pencarian) dengan term (dari dokumen), 0 jika tidak cocok
+func match(word string, term string) float64 {
+	switch {
+	case word == term:
+		return scoreExact
+	case strings.HasPrefix(term, word):
+		return scorePrefix
+	}
+
+	edits := maxEdits(word)
+	if edits == 0 {
+		return 0
+	}
+	if distance := distance([]rune(word), []rune(term), edits); distance <= edits {
+		return scoreFuzzy
+	}
+	return 0
+}
+
+// maxEdits adalah jumlah salah ketik yang ditoleransi, kata pendek harus persis
+func maxEdits(word string) int {
+	switch length := len([]rune(word)); {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// distance menghitung jarak Damerau-Levenshtein (optimal string alignment): sisip, hapus,
+// ganti dan tukar dua huruf bersebelahan. Berhenti lebih awal jika jaraknya pasti melebihi limit
+func distance(a []rune, b []rune, limit int) int {
+	if abs(len(a)-len(b)) > limit {
+		return limit + 1
+	}
+
+	previous2 := make([]int, len(b)+1)
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		lowest := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+			lowest = min(lowest, current[j])
+		}
+		if lowest > limit {
+			return limit + 1
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(b)]
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// Highlight membungkus kata di text yang ada di terms dengan <em></em>, teks lainnya di-escape
+// sehingga hasilnya aman ditampilkan sebagai HTML
+func Highlight(text string, terms []string) string {
+	matched := map[string]bool{}
+	for _, term := range terms {
+		matched[term] = true
+	}
+
+	var builder strings.Builder
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		isWord := unicode.IsLetter(runes[start]) || unicode.IsDigit(runes[start])
+		for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end])) == isWord {
+			end++
+		}
+
+		part := string(runes[start:end])
+		if isWord && matched[strings.ToLower(part)] {
+			builder.WriteString("<em>" + html.EscapeString(part) + "</em>")
+		} else {
+			builder.WriteString(html.EscapeString(part))
+		}
+		start = end
+	}
+	return builder.String()
+}
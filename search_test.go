@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/search"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchIndex(t *testing.T) {
+	assert.Equal(t, []string{"bagus", "eko"}, search.Tokenize("Bagus, EKO!"))
+	assert.Equal(t, []string{"o", "neil", "42"}, search.Tokenize("O'Neil-42"))
+
+	index := search.NewIndex()
+	index.Add("1", map[string]string{"first_name": "Bagus", "last_name": "Wicaksono"})
+	index.Add("2", map[string]string{"first_name": "Bagas", "last_name": "Wicak"})
+	index.Add("3", map[string]string{"first_name": "Eko", "last_name": "Kurniawan"})
+
+	// persis lebih tinggi dari awalan, awalan lebih tinggi dari salah ketik
+	hits := index.Search("wicak", 0)
+	assert.Equal(t, 2, len(hits))
+	assert.Equal(t, "2", hits[0].ID)
+	assert.Equal(t, "1", hits[1].ID)
+	assert.Equal(t, []string{"wicaksono"}, hits[1].Terms["last_name"])
+
+	hits = index.Search("bgaus wicaksono", 0)
+	assert.Equal(t, "1", hits[0].ID)
+	assert.Equal(t, 1.5, hits[0].Score)
+
+	// kata pendek tidak ditoleransi salah ketik
+	assert.Equal(t, 0, len(index.Search("eka", 0)))
+	assert.Equal(t, 1, len(index.Search("kurniawam", 0)))
+	assert.Equal(t, 1, len(index.Search("bagus bagas", 1)))
+
+	assert.Equal(t, "<em>Bagus</em> &lt;b&gt;<em>bagus</em>&lt;/b&gt;", search.Highlight("Bagus <b>bagus</b>", []string{"bagus"}))
+	assert.Equal(t, "Eko <em>Kurniawan</em>-<em>Khannedy</em>", search.Highlight("Eko Kurniawan-Khannedy", []string{"kurniawan", "khannedy"}))
+}
+
+func searchUsers(t *testing.T, store repository.Store, q string) (int, []model.UserSearchResponse) {
+	request := httptest.NewRequest("GET", "/api/users/search?"+url.Values{"q": {q}}.Encode(), nil)
+	response, err := newUserApp(store).Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	searchResponse := new(model.WebResponse[[]model.UserSearchResponse])
+	if response.StatusCode == 200 {
+		assert.Nil(t, json.Unmarshal(bytes, searchResponse))
+	}
+	return response.StatusCode, searchResponse.Data
+}
+
+func TestUserSearch(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	store := repository.NewGormStore(db)
+
+	status, users := searchUsers(t, store, "wicak")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "1", users[0].ID)
+	assert.Equal(t, "Wicaksono", users[0].Name.LastName)
+	assert.Equal(t, map[string]string{"last_name": "<em>Wicaksono</em>"}, users[0].Highlight)
+
+	// salah ketik di nama tengah
+	status, users = searchUsers(t, store, "tetsing")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "<em>Testing</em>", users[0].Highlight["middle_name"])
+
+	status, users = searchUsers(t, store, "user 11")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "11", users[0].ID)
+	assert.Greater(t, users[0].Score, users[1].Score)
+
+	status, _ = searchUsers(t, store, "")
+	assert.Equal(t, 422, status)
+
+	status, users = searchUsers(t, store, "!!!")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 0, len(users))
+}
+
+// tanpa FULLTEXT kandidat tetap disaring dan dibatasi, tidak membaca seluruh tabel
+func TestUserSearchCandidates(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	memory := repository.NewMemoryStore()
+	assert.Nil(t, memory.Users().Create(context.Background(), &entity.User{ID: "1", Name: entity.Name{FirstName: "Bagus"}}))
+	for _, id := range []string{"2", "3", "4"} {
+		assert.Nil(t, memory.Users().Create(context.Background(), &entity.User{ID: id, Name: entity.Name{FirstName: "User" + id}}))
+	}
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": memory}
+
+	for name, store := range stores {
+		users, err := store.Users().Search(context.Background(), []string{"user"}, 3)
+		assert.Nil(t, err, name)
+		assert.Equal(t, 3, len(users), name)
+
+		// kandidat yang mengandung kata utuh diurutkan lebih dulu
+		users, err = store.Users().Search(context.Background(), []string{"usxx", "user4"}, 1)
+		assert.Nil(t, err, name)
+		assert.Equal(t, "4", users[0].ID, name)
+
+		users, err = store.Users().Search(context.Background(), []string{"zz"}, 10)
+		assert.Nil(t, err, name)
+		assert.Empty(t, users, name)
+	}
+}
+
+// MemoryStore tidak punya index, hasilnya harus sama dengan fallback di database
+func TestUserSearchMemoryStore(t *testing.T) {
+	store := repository.NewMemoryStore()
+	assert.Nil(t, store.Users().Create(context.Background(), &entity.User{ID: "memory-1", Name: entity.Name{FirstName: "Bagus", LastName: "Wicaksono"}}))
+	assert.Nil(t, store.Users().Create(context.Background(), &entity.User{ID: "memory-2", Name: entity.Name{FirstName: "Eko"}}))
+
+	status, users := searchUsers(t, store, "bagsu")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, 1, len(users))
+	assert.Equal(t, "memory-1", users[0].ID)
+	assert.Equal(t, map[string]string{"first_name": "<em>Bagus</em>"}, users[0].Highlight)
+}
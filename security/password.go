@@ -0,0 +1,63 @@
+package security
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"belajar-golang-fiber/exception"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const DefaultPasswordCost = bcrypt.DefaultCost
+
+// PasswordHasher melakukan hash password menggunakan bcrypt.
+// Cost bisa dinaikkan kapan saja, hash lama akan di-rehash ketika login berhasil
+type PasswordHasher struct {
+	Cost int
+}
+
+func NewPasswordHasher(cost int) *PasswordHasher {
+	if cost < bcrypt.MinCost {
+		cost = DefaultPasswordCost
+	}
+	return &PasswordHasher{Cost: cost}
+}
+
+// Hash mengembalikan error validasi (422) untuk password lebih dari 72 byte, batas input bcrypt
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", exception.Validation("password must be at most 72 bytes")
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(hashed), nil
+}
+
+// Verify mengecek password terhadap nilai yang tersimpan di database.
+// needsRehash bernilai true jika password cocok tetapi nilai yang tersimpan masih plaintext
+// (row lama) atau cost-nya berbeda dengan cost saat ini
+func (h *PasswordHasher) Verify(stored string, password string) (ok bool, needsRehash bool) {
+	if !IsHashed(stored) {
+		ok = subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+		return ok, ok
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) != nil {
+		return false, false
+	}
+
+	cost, err := bcrypt.Cost([]byte(stored))
+	return true, err != nil || cost != h.Cost
+}
+
+// IsHashed mendeteksi apakah nilai kolom password sudah berupa hash bcrypt
+func IsHashed(value string) bool {
+	if len(value) != 60 {
+		return false
+	}
+	return strings.HasPrefix(value, "$2a$") || strings.HasPrefix(value, "$2b$") || strings.HasPrefix(value, "$2y$")
+}
@@ -0,0 +1,53 @@
+package security
+
+import (
+	"slices"
+	"strings"
+)
+
+// Permission yang dikenal aplikasi dengan format <resource>:<action>. Role juga boleh memakai
+// wildcard "*" (semua permission) atau "<resource>:*" (semua action pada resource tersebut)
+const (
+	PermUsersRead   = "users:read"
+	PermUsersWrite  = "users:write" // membuat, mengubah dan menghapus user lain
+	PermUsersAdmin  = "users:admin" // melihat dan me-restore user yang sudah dihapus
+	PermFilesRead   = "files:read"
+	PermFilesWrite  = "files:write"
+	PermFilesAdmin  = "files:admin" // membaca file yang di-upload user lain
+	PermRolesManage = "roles:manage"
+	PermLogsManage  = "logs:manage" // mengubah level log saat aplikasi berjalan
+)
+
+var Permissions = []string{
+	PermUsersRead, PermUsersWrite, PermUsersAdmin,
+	PermFilesRead, PermFilesWrite, PermFilesAdmin,
+	PermRolesManage, PermLogsManage,
+}
+
+// DefaultRole diberikan ke setiap user baru (register dan create user)
+const DefaultRole = "user"
+
+// PermissionSet adalah gabungan permission dari semua role milik user
+type PermissionSet []string
+
+// Has mengecek permission dengan memperhitungkan wildcard
+func (p PermissionSet) Has(permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, granted := range p {
+		if granted == "*" || granted == permission || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidPermission mengecek permission yang boleh disimpan di role: permission yang dikenal atau wildcard-nya
+func ValidPermission(permission string) bool {
+	if permission == "*" || slices.Contains(Permissions, permission) {
+		return true
+	}
+	resource, found := strings.CutSuffix(permission, ":*")
+	return found && slices.ContainsFunc(Permissions, func(known string) bool {
+		return strings.HasPrefix(known, resource+":")
+	})
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"belajar-golang-fiber/audit"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/validation"
+)
+
+type AuthService interface {
+	Register(ctx context.Context, request *model.RegisterRequest) (*model.RegisterResponse, error)
+	Login(ctx context.Context, request *model.LoginRequest) (*model.UserResponse, error)
+	Current(ctx context.Context, userId string) (*model.UserResponse, error)
+}
+
+type authServiceImpl struct {
+	Store  repository.Store
+	Hasher *security.PasswordHasher
+}
+
+func NewAuthService(store repository.Store, hasher *security.PasswordHasher) AuthService {
+	return &authServiceImpl{Store: store, Hasher: hasher}
+}
+
+func (s *authServiceImpl) Register(ctx context.Context, request *model.RegisterRequest) (*model.RegisterResponse, error) {
+	name := model.ParseName(request.Name)
+	if err := validateParsedName(name); err != nil {
+		return nil, err
+	}
+
+	password, err := s.Hasher.Hash(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.User{
+		ID:       request.Username,
+		Password: password,
+		Name:     name,
+	}
+	err = s.Store.Transaction(ctx, func(store repository.Store) error {
+		if err := store.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		if err := store.Roles().Assign(ctx, user.ID, security.DefaultRole); err != nil {
+			return err
+		}
+		return recordLog(ctx, store, user.ID, audit.ActionRegister, audit.Diff(nil, &user))
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, exception.Conflict("username already registered")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.RegisterResponse{
+		Username:   user.ID,
+		FirstName:  user.Name.FirstName,
+		MiddleName: user.Name.MiddleName,
+		LastName:   user.Name.LastName,
+	}, nil
+}
+
+func (s *authServiceImpl) Login(ctx context.Context, request *model.LoginRequest) (*model.UserResponse, error) {
+	user, err := s.Store.Users().FindById(ctx, request.Username)
+	if errors.Is(err, repository.ErrNotFound) {
+		// tetap melakukan hash supaya waktu response user tidak ada dan password salah tidak jauh berbeda
+		s.Hasher.Hash(request.Password)
+		return nil, exception.Unauthorized("invalid username or password")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ok, needsRehash := s.Hasher.Verify(user.Password, request.Password)
+	if !ok {
+		return nil, exception.Unauthorized("invalid username or password")
+	}
+
+	// row lama yang masih plaintext atau cost bcrypt yang sudah berubah di-upgrade saat login berhasil
+	if needsRehash {
+		password, err := s.Hasher.Hash(request.Password)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.Store.Users().UpdatePassword(ctx, user.ID, password); err != nil {
+			return nil, err
+		}
+	}
+
+	response := model.ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *authServiceImpl) Current(ctx context.Context, userId string) (*model.UserResponse, error) {
+	user, err := s.Store.Users().FindById(ctx, userId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, exception.Unauthorized("login required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToUserResponse(user)
+	return &response, nil
+}
+
+// validateParsedName memastikan setiap bagian nama hasil ParseName muat di kolomnya, aturannya sama dengan
+// model.NameRequest. Batas name di RegisterRequest berlaku untuk nama lengkap, bukan per bagian
+func validateParsedName(name entity.Name) error {
+	parts := []struct {
+		label string
+		value string
+		max   int
+	}{
+		{"first name", name.FirstName, 255},
+		{"middle name", name.MiddleName, 100},
+		{"last name", name.LastName, 100},
+	}
+
+	result := &validation.Error{}
+	for _, part := range parts {
+		if utf8.RuneCountInString(part.value) > part.max {
+			result.Fields = append(result.Fields, validation.FieldError{
+				Field:   "name",
+				Code:    "max",
+				Param:   fmt.Sprint(part.max),
+				Message: fmt.Sprintf("name: %s must be at most %d characters", part.label, part.max),
+			})
+		}
+	}
+	if len(result.Fields) > 0 {
+		return result
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/storage"
+
+	"github.com/google/uuid"
+)
+
+type FileService interface {
+	// Upload menyimpan semua file sekaligus, jika satu file gagal tidak ada file yang tersimpan
+	Upload(ctx context.Context, uploadedBy string, files ...model.UploadFile) ([]model.FileResponse, error)
+	Get(ctx context.Context, id string) (*model.FileResponse, error)
+	// Open mengambil metadata file, isinya baru dibaca saat FileContent.Open dipanggil
+	Open(ctx context.Context, id string) (*model.FileContent, error)
+	// DownloadURL membuat URL download sementara yang bisa dibuka tanpa login
+	DownloadURL(ctx context.Context, id string) (*model.DownloadURLResponse, error)
+	// OpenSigned membuka file dari URL download milik aplikasi (storage tanpa presigned URL)
+	OpenSigned(ctx context.Context, id string, expires string, signature string) (*model.FileContent, error)
+}
+
+// sniffLength adalah jumlah byte awal yang dipakai http.DetectContentType
+const sniffLength = 512
+
+// extensions dipakai untuk nama file di storage berdasarkan hasil deteksi isi file
+var extensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+	"text/plain":      ".txt",
+}
+
+type fileServiceImpl struct {
+	Store         repository.Store
+	Storage       storage.Storage
+	Signer        *security.URLSigner
+	Config        config.UploadConfig
+	StorageConfig config.StorageConfig
+}
+
+func NewFileService(store repository.Store, fileStorage storage.Storage, signer *security.URLSigner, uploadConfig config.UploadConfig, storageConfig config.StorageConfig) FileService {
+	return &fileServiceImpl{
+		Store:         store,
+		Storage:       fileStorage,
+		Signer:        signer,
+		Config:        uploadConfig,
+		StorageConfig: storageConfig,
+	}
+}
+
+func (s *fileServiceImpl) Upload(ctx context.Context, uploadedBy string, files ...model.UploadFile) ([]model.FileResponse, error) {
+	if len(files) == 0 {
+		return nil, exception.Validation("no file uploaded")
+	}
+	if len(files) > s.Config.MaxFiles {
+		return nil, exception.Validation(fmt.Sprintf("at most %d files can be uploaded at once", s.Config.MaxFiles))
+	}
+
+	var stored []entity.File
+	cleanup := func() {
+		for _, file := range stored {
+			s.Storage.Delete(ctx, file.StorageName)
+		}
+	}
+
+	for _, upload := range files {
+		file, err := s.write(ctx, upload)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		file.UploadedBy = uploadedBy
+		stored = append(stored, *file)
+	}
+
+	err := s.Store.Transaction(ctx, func(store repository.Store) error {
+		for i := range stored {
+			if err := store.Files().Create(ctx, &stored[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	responses := make([]model.FileResponse, len(stored))
+	for i := range stored {
+		responses[i] = model.ToFileResponse(&stored[i])
+	}
+	return responses, nil
+}
+
+func (s *fileServiceImpl) Get(ctx context.Context, id string) (*model.FileResponse, error) {
+	file, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToFileResponse(file)
+	return &response, nil
+}
+
+func (s *fileServiceImpl) Open(ctx context.Context, id string) (*model.FileContent, error) {
+	file, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FileContent{
+		FileResponse: model.ToFileResponse(file),
+		Open: func(offset int64, length int64) (io.ReadCloser, error) {
+			if offset == 0 && length == file.Size {
+				return s.Storage.Open(ctx, file.StorageName)
+			}
+			return s.Storage.OpenRange(ctx, file.StorageName, offset, length)
+		},
+	}, nil
+}
+
+func (s *fileServiceImpl) DownloadURL(ctx context.Context, id string) (*model.DownloadURLResponse, error) {
+	file, err := s.find(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.StorageConfig.SignedURLTTL
+	expiresAt := time.Now().Add(ttl)
+	if presigner, ok := s.Storage.(storage.Presigner); ok {
+		signedURL, err := presigner.PresignGet(ctx, file.StorageName, file.OriginalName, file.ContentType, ttl)
+		if err != nil {
+			return nil, err
+		}
+		return &model.DownloadURLResponse{URL: signedURL, ExpiresAt: expiresAt}, nil
+	}
+
+	query := s.Signer.Sign(file.ID, expiresAt)
+	signedURL := strings.TrimSuffix(s.StorageConfig.DownloadURL, "/") + "/" + url.PathEscape(file.ID) + "?" + query.Encode()
+	return &model.DownloadURLResponse{URL: signedURL, ExpiresAt: expiresAt}, nil
+}
+
+func (s *fileServiceImpl) OpenSigned(ctx context.Context, id string, expires string, signature string) (*model.FileContent, error) {
+	if err := s.Signer.Verify(id, expires, signature); err != nil {
+		return nil, exception.Forbidden("download link is invalid or expired")
+	}
+	return s.Open(ctx, id)
+}
+
+func (s *fileServiceImpl) find(ctx context.Context, id string) (*entity.File, error) {
+	file, err := s.Store.Files().FindById(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, exception.NotFound("file not found")
+	}
+	return file, err
+}
+
+// write mengecek isi file (jenis, ukuran, checksum) di file sementara lalu menyimpannya ke storage.
+// Jenis file ditentukan dari isi file, nama dari client tidak pernah dipakai sebagai key storage
+func (s *fileServiceImpl) write(ctx context.Context, upload model.UploadFile) (*entity.File, error) {
+	name := SanitizeFilename(upload.Name)
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(upload.Reader, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, exception.Validation(fmt.Sprintf("file %s is empty", name))
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !slices.Contains(s.Config.Types(), mediaType) {
+		return nil, exception.UnsupportedMediaType(fmt.Sprintf("file %s has unsupported type %s", name, contentType))
+	}
+
+	temp, err := os.CreateTemp(s.Config.TempDir, "upload-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(temp.Name())
+	defer temp.Close()
+
+	// baca satu byte melebihi batas untuk mengetahui file terlalu besar tanpa membaca sisa body
+	limit := int64(s.Config.MaxSize)
+	reader := io.LimitReader(io.MultiReader(bytes.NewReader(head), upload.Reader), limit+1)
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(temp, hash), reader)
+	if err != nil {
+		return nil, err
+	}
+	if size > limit {
+		return nil, exception.TooLarge(fmt.Sprintf("file %s is larger than %d bytes", name, limit))
+	}
+	if _, err := temp.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	id := uuid.NewString()
+	storageName := id + extensions[mediaType]
+	if err := s.Storage.Put(ctx, storageName, temp, size, contentType); err != nil {
+		return nil, err
+	}
+
+	return &entity.File{
+		ID:           id,
+		OriginalName: name,
+		StorageName:  storageName,
+		ContentType:  contentType,
+		Size:         size,
+		Checksum:     hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// SanitizeFilename membersihkan nama file dari client untuk disimpan sebagai metadata dan dipakai
+// di header Content-Disposition: folder dibuang, karakter kontrol dan karakter khusus dihapus, maksimal 255 byte
+func SanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimLeft(strings.TrimSpace(name), ".")
+
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" {
+		return "file"
+	}
+	return name
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/validation"
+)
+
+type RoleService interface {
+	// List menampilkan semua role, atau hanya role milik user jika userId diisi
+	List(ctx context.Context, userId string) ([]model.RoleResponse, error)
+	Get(ctx context.Context, id string) (*model.RoleResponse, error)
+	Create(ctx context.Context, request *model.CreateRoleRequest) (*model.RoleResponse, error)
+	Update(ctx context.Context, id string, request *model.UpdateRoleRequest) (*model.RoleResponse, error)
+	Delete(ctx context.Context, id string) error
+	Assign(ctx context.Context, userId string, roleId string) error
+	Revoke(ctx context.Context, userId string, roleId string) error
+	// Permissions dipakai middleware.NewPermissions untuk memuat permission user yang login,
+	// user yang sudah dihapus ditolak dengan 401
+	Permissions(ctx context.Context, userId string) ([]string, error)
+}
+
+type roleServiceImpl struct {
+	Store repository.Store
+}
+
+func NewRoleService(store repository.Store) RoleService {
+	return &roleServiceImpl{Store: store}
+}
+
+func (s *roleServiceImpl) List(ctx context.Context, userId string) ([]model.RoleResponse, error) {
+	var roles []entity.Role
+	var err error
+	if userId != "" {
+		roles, err = s.Store.Roles().FindByUserId(ctx, userId)
+	} else {
+		roles, err = s.Store.Roles().FindAll(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]model.RoleResponse, len(roles))
+	for i := range roles {
+		responses[i] = model.ToRoleResponse(&roles[i])
+	}
+	return responses, nil
+}
+
+func (s *roleServiceImpl) Get(ctx context.Context, id string) (*model.RoleResponse, error) {
+	role, err := findRole(ctx, s.Store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToRoleResponse(role)
+	return &response, nil
+}
+
+func (s *roleServiceImpl) Create(ctx context.Context, request *model.CreateRoleRequest) (*model.RoleResponse, error) {
+	permissions, err := rolePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := entity.Role{ID: request.ID, Description: request.Description, Permissions: permissions}
+	err = s.Store.Roles().Create(ctx, &role)
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, exception.Conflict("role already exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToRoleResponse(&role)
+	return &response, nil
+}
+
+func (s *roleServiceImpl) Update(ctx context.Context, id string, request *model.UpdateRoleRequest) (*model.RoleResponse, error) {
+	permissions, err := rolePermissions(request.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	var role *entity.Role
+	err = s.Store.Transaction(ctx, func(store repository.Store) error {
+		var err error
+		role, err = findRole(ctx, store, id)
+		if err != nil {
+			return err
+		}
+
+		role.Description = request.Description
+		role.Permissions = permissions
+		return store.Roles().Save(ctx, role)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToRoleResponse(role)
+	return &response, nil
+}
+
+func (s *roleServiceImpl) Delete(ctx context.Context, id string) error {
+	err := s.Store.Transaction(ctx, func(store repository.Store) error {
+		return store.Roles().Delete(ctx, id)
+	})
+	if errors.Is(err, repository.ErrNotFound) {
+		return exception.NotFound("role not found")
+	}
+	return err
+}
+
+func (s *roleServiceImpl) Assign(ctx context.Context, userId string, roleId string) error {
+	if _, err := findUser(ctx, s.Store, userId); err != nil {
+		return err
+	}
+	if _, err := findRole(ctx, s.Store, roleId); err != nil {
+		return err
+	}
+	return s.Store.Roles().Assign(ctx, userId, roleId)
+}
+
+func (s *roleServiceImpl) Revoke(ctx context.Context, userId string, roleId string) error {
+	err := s.Store.Roles().Revoke(ctx, userId, roleId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return exception.NotFound("user does not have this role")
+	}
+	return err
+}
+
+// Permissions menolak session milik user yang sudah dihapus (soft delete) dengan 401. Session dan role-nya
+// tidak dihapus sehingga akses kembali seperti semula jika user di-restore
+func (s *roleServiceImpl) Permissions(ctx context.Context, userId string) ([]string, error) {
+	if userId != "" {
+		if _, err := s.Store.Users().FindById(ctx, userId); errors.Is(err, repository.ErrNotFound) {
+			return nil, exception.Unauthorized("user has been deleted")
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return s.Store.Roles().Permissions(ctx, userId)
+}
+
+func findRole(ctx context.Context, store repository.Store, id string) (*entity.Role, error) {
+	role, err := store.Roles().FindById(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, exception.NotFound("role not found")
+	}
+	return role, err
+}
+
+// rolePermissions memastikan hanya permission yang dikenal (atau wildcard-nya) yang disimpan, duplikat dibuang
+func rolePermissions(values []string) ([]entity.RolePermission, error) {
+	var permissions []entity.RolePermission
+	var fieldErrors []validation.FieldError
+	var seen []string
+	for i, value := range values {
+		if !security.ValidPermission(value) {
+			fieldErrors = append(fieldErrors, validation.FieldError{
+				Field:   fmt.Sprintf("permissions[%d]", i),
+				Code:    "oneof",
+				Param:   strings.Join(security.Permissions, " "),
+				Message: fmt.Sprintf("permission %q is unknown", value),
+			})
+			continue
+		}
+		if !slices.Contains(seen, value) {
+			seen = append(seen, value)
+			permissions = append(permissions, entity.RolePermission{Permission: value})
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &validation.Error{Fields: fieldErrors}
+	}
+	return permissions, nil
+}
@@ -0,0 +1,322 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+
+	"github.com/google/uuid"
+)
+
+// UploadService menangani upload bertahap (resumable) seperti protokol tus: sesi dibuat dengan
+// ukuran total, data dikirim per bagian mulai dari offset terakhir, dan jika koneksi terputus
+// client cukup menanyakan offset lalu melanjutkan. Sesi yang tidak dilanjutkan selama
+// UploadConfig.Expiration dianggap tidak ada dan dihapus oleh Purge
+type UploadService interface {
+	Create(ctx context.Context, uploadedBy string, request *model.CreateUploadRequest) (*model.UploadResponse, error)
+	Get(ctx context.Context, uploadedBy string, id string) (*model.UploadResponse, error)
+	// Append menulis data mulai dari offset, jika data sudah lengkap file disimpan lewat FileService
+	Append(ctx context.Context, uploadedBy string, id string, offset int64, reader io.Reader) (*model.UploadResponse, error)
+	Delete(ctx context.Context, uploadedBy string, id string) error
+	// Purge menghapus sesi upload yang terakhir diubah sebelum waktu tertentu beserta file sementaranya
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+type uploadServiceImpl struct {
+	Store  repository.Store
+	Files  FileService
+	Config config.UploadConfig
+
+	mutex  sync.Mutex
+	active map[string]bool
+}
+
+func NewUploadService(store repository.Store, files FileService, uploadConfig config.UploadConfig) UploadService {
+	return &uploadServiceImpl{
+		Store:  store,
+		Files:  files,
+		Config: uploadConfig,
+		active: map[string]bool{},
+	}
+}
+
+func (s *uploadServiceImpl) Create(ctx context.Context, uploadedBy string, request *model.CreateUploadRequest) (*model.UploadResponse, error) {
+	if request.Length <= 0 {
+		return nil, exception.Validation("upload length must be greater than 0")
+	}
+	if request.Length > int64(s.Config.MaxSize) {
+		return nil, exception.TooLarge(fmt.Sprintf("upload is larger than %d bytes", s.Config.MaxSize))
+	}
+
+	upload := entity.Upload{
+		ID:         uuid.NewString(),
+		Name:       SanitizeFilename(request.Name),
+		Length:     request.Length,
+		UploadedBy: uploadedBy,
+	}
+	part, err := os.OpenFile(s.path(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, err
+	}
+	part.Close()
+
+	if err := s.Store.Uploads().Create(ctx, &upload); err != nil {
+		os.Remove(s.path(upload.ID))
+		return nil, err
+	}
+
+	response := s.toResponse(&upload)
+	return &response, nil
+}
+
+func (s *uploadServiceImpl) Get(ctx context.Context, uploadedBy string, id string) (*model.UploadResponse, error) {
+	upload, err := s.find(ctx, uploadedBy, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.toResponse(upload)
+	return &response, nil
+}
+
+func (s *uploadServiceImpl) Append(ctx context.Context, uploadedBy string, id string, offset int64, reader io.Reader) (*model.UploadResponse, error) {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	upload, err := s.find(ctx, uploadedBy, id)
+	if err != nil {
+		return nil, err
+	}
+	if offset != upload.Offset {
+		return nil, exception.Conflict(fmt.Sprintf("upload offset is %d", upload.Offset))
+	}
+
+	written, err := s.write(upload, reader)
+	if written > 0 {
+		upload.Offset += written
+		upload.UpdatedAt = time.Now()
+		if updateErr := s.Store.Uploads().UpdateOffset(ctx, upload.ID, upload.Offset); updateErr != nil {
+			return nil, updateErr
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.toResponse(upload)
+	if upload.Offset < upload.Length {
+		return &response, nil
+	}
+
+	file, err := s.complete(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	response.File = file
+	return &response, nil
+}
+
+func (s *uploadServiceImpl) Delete(ctx context.Context, uploadedBy string, id string) error {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, err := s.find(ctx, uploadedBy, id); err != nil {
+		return err
+	}
+	return s.discard(ctx, id)
+}
+
+// write menambahkan data ke file sementara. Data yang sudah diterima tetap disimpan walaupun
+// koneksi terputus di tengah jalan, sehingga client bisa melanjutkan dari offset terakhir
+func (s *uploadServiceImpl) write(upload *entity.Upload, reader io.Reader) (int64, error) {
+	part, err := os.OpenFile(s.path(upload.ID), os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, err
+	}
+	defer part.Close()
+
+	// sisa data dari request sebelumnya yang offset-nya belum sempat tersimpan dibuang
+	if err := part.Truncate(upload.Offset); err != nil {
+		return 0, err
+	}
+	if _, err := part.Seek(upload.Offset, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	remaining := upload.Length - upload.Offset
+	written, err := io.Copy(part, io.LimitReader(reader, remaining+1))
+	if written > remaining {
+		part.Truncate(upload.Offset)
+		return 0, exception.TooLarge(fmt.Sprintf("data exceeds upload length of %d bytes", upload.Length))
+	}
+	if syncErr := part.Sync(); err == nil {
+		err = syncErr
+	}
+	return written, err
+}
+
+// complete menyimpan file yang sudah lengkap lewat FileService (cek jenis, ukuran dan checksum yang sama
+// dengan upload biasa) lalu menghapus sesi upload
+func (s *uploadServiceImpl) complete(ctx context.Context, upload *entity.Upload) (*model.FileResponse, error) {
+	part, err := os.Open(s.path(upload.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.Files.Upload(ctx, upload.UploadedBy, model.UploadFile{Name: upload.Name, Reader: part})
+	part.Close()
+	if err != nil {
+		// isi file tidak akan berubah, sesi yang ditolak tidak bisa dilanjutkan
+		var appError *exception.Error
+		if errors.As(err, &appError) && appError.Kind != exception.KindInternal {
+			s.discard(ctx, upload.ID)
+		}
+		return nil, err
+	}
+
+	if err := s.discard(ctx, upload.ID); err != nil {
+		return nil, err
+	}
+	return &files[0], nil
+}
+
+func (s *uploadServiceImpl) discard(ctx context.Context, id string) error {
+	if err := s.Store.Uploads().Delete(ctx, id); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *uploadServiceImpl) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.Store.Uploads().FindUpdatedBefore(ctx, before, purgeBatch)
+		if err != nil || len(ids) == 0 {
+			return purged, err
+		}
+
+		discarded := 0
+		for _, id := range ids {
+			ok, err := s.purge(ctx, id, before)
+			if err != nil {
+				return purged, err
+			}
+			if ok {
+				discarded++
+			}
+		}
+		purged += discarded
+		// semua sesi di batch ini sedang ditulis, sisanya dicoba lagi di jadwal berikutnya
+		if discarded == 0 {
+			return purged, nil
+		}
+	}
+}
+
+// purge menghapus satu sesi jika tidak sedang ditulis dan masih kedaluwarsa setelah dikunci
+func (s *uploadServiceImpl) purge(ctx context.Context, id string, before time.Time) (bool, error) {
+	unlock, err := s.lock(id)
+	if err != nil {
+		return false, nil
+	}
+	defer unlock()
+
+	upload, err := s.Store.Uploads().FindById(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil || !upload.UpdatedAt.Before(before) {
+		return false, err
+	}
+	return true, s.discard(ctx, id)
+}
+
+// RunUploadPurge menjalankan Purge setiap interval sampai ctx selesai, sesi yang tidak dilanjutkan
+// selama expiration dihapus
+func RunUploadPurge(ctx context.Context, uploadService UploadService, interval time.Duration, expiration time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := uploadService.Purge(ctx, time.Now().Add(-expiration))
+		if err != nil {
+			log.Printf("purge expired uploads: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d expired uploads", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// find juga menolak sesi yang sudah kedaluwarsa walaupun belum dihapus oleh Purge
+func (s *uploadServiceImpl) find(ctx context.Context, uploadedBy string, id string) (*entity.Upload, error) {
+	upload, err := s.Store.Uploads().FindById(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && (upload.UploadedBy != uploadedBy || s.expired(upload))) {
+		return nil, exception.NotFound("upload not found")
+	}
+	return upload, err
+}
+
+func (s *uploadServiceImpl) expiresAt(upload *entity.Upload) time.Time {
+	return upload.UpdatedAt.Add(s.Config.Expiration)
+}
+
+func (s *uploadServiceImpl) expired(upload *entity.Upload) bool {
+	return !time.Now().Before(s.expiresAt(upload))
+}
+
+func (s *uploadServiceImpl) toResponse(upload *entity.Upload) model.UploadResponse {
+	response := model.ToUploadResponse(upload)
+	response.ExpiresAt = s.expiresAt(upload)
+	return response
+}
+
+// lock mencegah dua request menulis ke sesi upload yang sama secara bersamaan
+func (s *uploadServiceImpl) lock(id string) (func(), error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.active[id] {
+		return nil, exception.Conflict("upload is being written by another request")
+	}
+	s.active[id] = true
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		delete(s.active, id)
+	}, nil
+}
+
+func (s *uploadServiceImpl) path(id string) string {
+	dir := s.Config.TempDir
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "upload-"+id+".part")
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"belajar-golang-fiber/audit"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/query"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/search"
+	"belajar-golang-fiber/security"
+)
+
+const (
+	defaultSearchLimit = 20
+	// jumlah kandidat dari database untuk setiap hasil yang diminta, sisanya disaring ulang oleh search.Index
+	searchCandidates = 5
+	// jumlah user yang dihapus permanen dalam satu transaksi
+	purgeBatch = 100
+)
+
+type UserService interface {
+	Create(ctx context.Context, request *model.CreateUserRequest) (*model.UserResponse, error)
+	Get(ctx context.Context, id string) (*model.UserResponse, error)
+	// List dengan includeDeleted ikut menampilkan user yang sudah dihapus (permission users:admin)
+	List(ctx context.Context, q *query.Query, includeDeleted bool) ([]model.UserResponse, *model.PageMetadata, error)
+	Search(ctx context.Context, request *model.SearchUserRequest) ([]model.UserSearchResponse, error)
+	// Update hanya berhasil jika versi user ada di versions (dari If-Match), versions nil berarti tanpa pengecekan
+	Update(ctx context.Context, id string, request *model.UpdateUserRequest, versions []int64) (*model.UserResponse, error)
+	Delete(ctx context.Context, id string) error
+	Restore(ctx context.Context, id string) (*model.UserResponse, error)
+	// Purge menghapus permanen user yang dihapus sebelum waktu tertentu beserta log-nya
+	Purge(ctx context.Context, before time.Time) (int, error)
+	Logs(ctx context.Context, id string, page int, size int) ([]model.UserLogResponse, *model.PageMetadata, error)
+}
+
+type userServiceImpl struct {
+	Store  repository.Store
+	Hasher *security.PasswordHasher
+}
+
+func NewUserService(store repository.Store, hasher *security.PasswordHasher) UserService {
+	return &userServiceImpl{Store: store, Hasher: hasher}
+}
+
+func (s *userServiceImpl) Create(ctx context.Context, request *model.CreateUserRequest) (*model.UserResponse, error) {
+	password, err := s.Hasher.Hash(request.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := entity.User{
+		ID:       request.ID,
+		Password: password,
+		Name: entity.Name{
+			FirstName:  request.Name.FirstName,
+			MiddleName: request.Name.MiddleName,
+			LastName:   request.Name.LastName,
+		},
+	}
+	err = s.Store.Transaction(ctx, func(store repository.Store) error {
+		if err := store.Users().Create(ctx, &user); err != nil {
+			return err
+		}
+		if err := store.Roles().Assign(ctx, user.ID, security.DefaultRole); err != nil {
+			return err
+		}
+		return recordLog(ctx, store, user.ID, audit.ActionCreate, audit.Diff(nil, &user))
+	})
+	if errors.Is(err, repository.ErrDuplicate) {
+		return nil, exception.Conflict("user already exists")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToUserResponse(&user)
+	return &response, nil
+}
+
+func (s *userServiceImpl) Get(ctx context.Context, id string) (*model.UserResponse, error) {
+	user, err := findUser(ctx, s.Store, id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *userServiceImpl) List(ctx context.Context, q *query.Query, includeDeleted bool) ([]model.UserResponse, *model.PageMetadata, error) {
+	users := s.Store.Users()
+	if includeDeleted {
+		users = users.Unscoped()
+	}
+
+	result, page, err := users.FindPage(ctx, q)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]model.UserResponse, len(result))
+	for i := range result {
+		responses[i] = model.ToUserResponse(&result[i])
+	}
+	return responses, model.ToPageMetadata(page), nil
+}
+
+func (s *userServiceImpl) Search(ctx context.Context, request *model.SearchUserRequest) ([]model.UserSearchResponse, error) {
+	limit := request.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	terms := search.Tokenize(request.Query)
+	if len(terms) == 0 {
+		return []model.UserSearchResponse{}, nil
+	}
+
+	candidates, err := s.Store.Users().Search(ctx, terms, limit*searchCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	// peringkat, toleransi salah ketik dan highlight dihitung dengan cara yang sama untuk semua database
+	index := search.NewIndex()
+	users := make(map[string]*entity.User, len(candidates))
+	for i := range candidates {
+		user := &candidates[i]
+		users[user.ID] = user
+		index.Add(user.ID, nameFields(user))
+	}
+
+	hits := index.Search(request.Query, limit)
+	responses := make([]model.UserSearchResponse, len(hits))
+	for i, hit := range hits {
+		user := users[hit.ID]
+		fields := nameFields(user)
+		highlight := make(map[string]string, len(hit.Terms))
+		for field, matched := range hit.Terms {
+			highlight[field] = search.Highlight(fields[field], matched)
+		}
+		responses[i] = model.UserSearchResponse{UserResponse: model.ToUserResponse(user), Score: hit.Score, Highlight: highlight}
+	}
+	return responses, nil
+}
+
+func nameFields(user *entity.User) map[string]string {
+	return map[string]string{
+		"first_name":  user.Name.FirstName,
+		"middle_name": user.Name.MiddleName,
+		"last_name":   user.Name.LastName,
+	}
+}
+
+func (s *userServiceImpl) Update(ctx context.Context, id string, request *model.UpdateUserRequest, versions []int64) (*model.UserResponse, error) {
+	var password string
+	if request.Password != nil {
+		hashed, err := s.Hasher.Hash(*request.Password)
+		if err != nil {
+			return nil, err
+		}
+		password = hashed
+	}
+
+	var user *entity.User
+	err := s.Store.Transaction(ctx, func(store repository.Store) error {
+		var err error
+		user, err = findUser(ctx, store, id)
+		if err != nil {
+			return err
+		}
+		if versions != nil && !slices.Contains(versions, user.Version) {
+			return exception.PreconditionFailed("user has been modified, reload and try again")
+		}
+		before := *user
+
+		// hanya field yang dikirim yang diubah, ID tidak akan pernah ikut ter-update karena <-:create
+		if request.Password != nil {
+			user.Password = password
+		}
+		if request.Name != nil {
+			if request.Name.FirstName != nil {
+				user.Name.FirstName = *request.Name.FirstName
+			}
+			if request.Name.MiddleName != nil {
+				user.Name.MiddleName = *request.Name.MiddleName
+			}
+			if request.Name.LastName != nil {
+				user.Name.LastName = *request.Name.LastName
+			}
+		}
+
+		if err := store.Users().Save(ctx, user); errors.Is(err, repository.ErrConflict) {
+			return exception.PreconditionFailed("user has been modified, reload and try again")
+		} else if err != nil {
+			return err
+		}
+		if changes := audit.Diff(&before, user); len(changes) > 0 {
+			if err := recordLog(ctx, store, user.ID, audit.ActionUpdate, changes); err != nil {
+				return err
+			}
+		}
+		if request.Password != nil {
+			return recordLog(ctx, store, user.ID, audit.ActionPasswordChange, audit.PasswordChanged())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToUserResponse(user)
+	return &response, nil
+}
+
+// Delete hanya soft delete: role user tetap tersimpan tetapi tidak memberi permission dan setiap request
+// dari session user tersebut ditolak (RoleService.Permissions) sampai user di-restore
+func (s *userServiceImpl) Delete(ctx context.Context, id string) error {
+	return s.Store.Transaction(ctx, func(store repository.Store) error {
+		user, err := findUser(ctx, store, id)
+		if err != nil {
+			return err
+		}
+		if err := store.Users().Delete(ctx, user); err != nil {
+			return err
+		}
+		return recordLog(ctx, store, user.ID, audit.ActionDelete, audit.Diff(user, nil))
+	})
+}
+
+func (s *userServiceImpl) Restore(ctx context.Context, id string) (*model.UserResponse, error) {
+	var user *entity.User
+	err := s.Store.Transaction(ctx, func(store repository.Store) error {
+		if err := store.Users().Restore(ctx, id); errors.Is(err, repository.ErrNotFound) {
+			return exception.NotFound("deleted user not found")
+		} else if err != nil {
+			return err
+		}
+		if err := recordLog(ctx, store, id, audit.ActionRestore, nil); err != nil {
+			return err
+		}
+
+		var err error
+		user, err = findUser(ctx, store, id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := model.ToUserResponse(user)
+	return &response, nil
+}
+
+func (s *userServiceImpl) Purge(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.Store.Users().FindDeletedBefore(ctx, before, purgeBatch)
+		if err != nil || len(ids) == 0 {
+			return purged, err
+		}
+
+		// log dan role ikut dihapus di transaksi yang sama supaya tidak ada data tanpa user
+		err = s.Store.Transaction(ctx, func(store repository.Store) error {
+			for _, id := range ids {
+				if err := store.UserLogs().DeleteByUserId(ctx, id); err != nil {
+					return err
+				}
+				if err := store.Roles().RevokeAll(ctx, id); err != nil {
+					return err
+				}
+				if err := store.Users().Unscoped().Delete(ctx, &entity.User{ID: id}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+	}
+}
+
+// RunUserPurge menjalankan Purge setiap interval sampai ctx selesai, user yang dihapus lebih lama
+// dari retention dihapus permanen
+func RunUserPurge(ctx context.Context, userService UserService, interval time.Duration, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := userService.Purge(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("purge deleted users: %v", err)
+		} else if purged > 0 {
+			log.Printf("purged %d deleted users", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *userServiceImpl) Logs(ctx context.Context, id string, page int, size int) ([]model.UserLogResponse, *model.PageMetadata, error) {
+	logs, total, err := s.Store.UserLogs().FindByUserId(ctx, id, page, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	responses := make([]model.UserLogResponse, len(logs))
+	for i := range logs {
+		responses[i] = model.ToUserLogResponse(&logs[i])
+	}
+	return responses, model.NewPageMetadata(page, size, total), nil
+}
+
+func findUser(ctx context.Context, store repository.Store, id string) (*entity.User, error) {
+	user, err := store.Users().FindById(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, exception.NotFound("user not found")
+	}
+	return user, err
+}
+
+func recordLog(ctx context.Context, store repository.Store, userId string, action string, changes []audit.Change) error {
+	log, err := audit.NewLog(userId, action, changes)
+	if err != nil {
+		return err
+	}
+	return store.UserLogs().Create(ctx, log)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newAdminUserApp(store repository.Store) *fiber.App {
+	return newUserAppAs(store, map[string][]string{
+		"admin":  {"*"},
+		"user-1": {security.PermUsersRead, security.PermUsersWrite},
+	})
+}
+
+func requestAs(t *testing.T, app *fiber.App, method string, target string, userId string) (int, []byte) {
+	request := httptest.NewRequest(method, target, nil)
+	request.Header.Set("X-User", userId)
+	response, err := app.Test(request)
+	assert.Nil(t, err)
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	return response.StatusCode, bytes
+}
+
+func TestUserSoftDeleteRestore(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	userApp := newAdminUserApp(repository.NewGormStore(db))
+
+	status, _ := requestAs(t, userApp, "DELETE", "/api/users/1", "user-1")
+	assert.Equal(t, 204, status)
+	status, _ = requestAs(t, userApp, "GET", "/api/users/1", "user-1")
+	assert.Equal(t, 404, status)
+
+	// data dan log masih ada di database
+	user := entity.User{}
+	assert.Nil(t, db.Unscoped().Take(&user, "id = ?", "1").Error)
+	assert.True(t, user.DeletedAt.Valid)
+	var logs int64
+	assert.Nil(t, db.Model(&entity.UserLogs{}).Where("user_id = ?", "1").Count(&logs).Error)
+	assert.Equal(t, int64(1), logs)
+
+	usersResponse := new(model.WebResponse[[]model.UserResponse])
+	status, body := requestAs(t, userApp, "GET", "/api/users?limit=100", "user-1")
+	assert.Equal(t, 200, status)
+	assert.Nil(t, json.Unmarshal(body, usersResponse))
+	assert.Equal(t, int64(13), usersResponse.Paging.TotalItem)
+
+	status, _ = requestAs(t, userApp, "GET", "/api/users?include_deleted=true", "user-1")
+	assert.Equal(t, 403, status)
+	status, body = requestAs(t, userApp, "GET", "/api/users?include_deleted=true&id=1", "admin")
+	assert.Equal(t, 200, status)
+	assert.Nil(t, json.Unmarshal(body, usersResponse))
+	assert.Equal(t, 1, len(usersResponse.Data))
+	assert.NotNil(t, usersResponse.Data[0].DeletedAt)
+
+	status, _ = requestAs(t, userApp, "POST", "/api/users/1/restore", "user-1")
+	assert.Equal(t, 403, status)
+	status, body = requestAs(t, userApp, "POST", "/api/users/1/restore", "admin")
+	assert.Equal(t, 200, status)
+	userResponse := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(body, userResponse))
+	assert.Equal(t, "1", userResponse.Data.ID)
+	assert.Nil(t, userResponse.Data.DeletedAt)
+
+	status, _ = requestAs(t, userApp, "POST", "/api/users/1/restore", "admin")
+	assert.Equal(t, 404, status)
+	status, _ = requestAs(t, userApp, "GET", "/api/users/1", "user-1")
+	assert.Equal(t, 200, status)
+
+	var actions []string
+	assert.Nil(t, db.Model(&entity.UserLogs{}).Where("user_id = ?", "1").Order("id").Pluck("action", &actions).Error)
+	assert.Equal(t, []string{"delete", "restore"}, actions)
+}
+
+func TestUserPurge(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": repository.NewMemoryStore()}
+	ctx := context.Background()
+
+	for name, store := range stores {
+		userService := service.NewUserService(store, security.NewPasswordHasher(bcrypt.MinCost))
+		for _, id := range []string{"purge-old", "purge-new"} {
+			_, err := userService.Create(ctx, &model.CreateUserRequest{ID: id, Password: "rahasia123", Name: model.NameRequest{FirstName: "Purge"}})
+			assert.Nil(t, err, name)
+			assert.Nil(t, userService.Delete(ctx, id), name)
+		}
+
+		// batas waktu di masa depan: semua user yang sudah dihapus ikut dihapus permanen
+		purged, err := userService.Purge(ctx, time.Now().Add(time.Hour))
+		assert.Nil(t, err, name)
+		assert.Equal(t, 2, purged, name)
+
+		_, err = store.Users().Unscoped().FindById(ctx, "purge-old")
+		assert.ErrorIs(t, err, repository.ErrNotFound, name)
+		logs, total, err := store.UserLogs().FindByUserId(ctx, "purge-old", 1, 10)
+		assert.Nil(t, err, name)
+		assert.Equal(t, int64(0), total, name)
+		assert.Equal(t, 0, len(logs), name)
+
+		purged, err = userService.Purge(ctx, time.Now().Add(time.Hour))
+		assert.Nil(t, err, name)
+		assert.Equal(t, 0, purged, name)
+	}
+}
+
+func TestUserPurgeRetention(t *testing.T) {
+	db := testdb.New(t, usersFixture)
+	userService := service.NewUserService(repository.NewGormStore(db), security.NewPasswordHasher(bcrypt.MinCost))
+	ctx := context.Background()
+
+	assert.Nil(t, userService.Delete(ctx, "2"))
+	assert.Nil(t, userService.Delete(ctx, "3"))
+	assert.Nil(t, db.Unscoped().Model(&entity.User{}).Where("id = ?", "2").Update("deleted_at", time.Now().Add(-48*time.Hour)).Error)
+
+	purged, err := userService.Purge(ctx, time.Now().Add(-24*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, purged)
+
+	var ids []string
+	assert.Nil(t, db.Unscoped().Model(&entity.User{}).Where("deleted_at IS NOT NULL").Pluck("id", &ids).Error)
+	assert.Equal(t, []string{"3"}, ids)
+
+	// user yang sudah dihapus permanen tidak bisa di-restore
+	_, err = userService.Restore(ctx, "2")
+	assert.True(t, exception.Is(err, exception.KindNotFound))
+}
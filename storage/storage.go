@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"belajar-golang-fiber/config"
+)
+
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage menyimpan isi file berdasarkan key. Key selalu dibuat oleh aplikasi, bukan nama file dari client
+type Storage interface {
+	Put(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	// Open mengembalikan ErrNotFound jika key tidak ada, pemanggil wajib menutup reader
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// OpenRange membaca length byte mulai dari offset, dipakai untuk request HTTP Range
+	OpenRange(ctx context.Context, key string, offset int64, length int64) (io.ReadCloser, error)
+	// Delete tidak mengembalikan error jika key sudah tidak ada
+	Delete(ctx context.Context, key string) error
+}
+
+// Presigner diimplementasikan backend yang bisa membuat URL download langsung ke backend (contoh S3),
+// backend lain dilayani oleh aplikasi lewat URL yang ditandatangani security.URLSigner
+type Presigner interface {
+	PresignGet(ctx context.Context, key string, filename string, contentType string, expires time.Duration) (string, error)
+}
+
+// New membuat storage sesuai storage.driver di konfigurasi
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Driver {
+	case "local":
+		return NewLocal(cfg.Dir)
+	case "s3":
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+	}
+}
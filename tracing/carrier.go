@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderCarrier menghubungkan header fasthttp (request yang diterima fiber maupun request dari
+// fiber.Agent) dengan Propagator
+type HeaderCarrier struct {
+	Header *fasthttp.RequestHeader
+}
+
+func (c HeaderCarrier) Get(key string) string {
+	return string(c.Header.Peek(key))
+}
+
+func (c HeaderCarrier) Set(key string, value string) {
+	c.Header.Set(key, value)
+}
+
+func (c HeaderCarrier) Keys() []string {
+	var keys []string
+	c.Header.VisitAll(func(key, _ []byte) {
+		keys = append(keys, string(key))
+	})
+	return keys
+}
+
+// Inject menambahkan header traceparent dari span di ctx ke request keluar, contoh:
+//
+//	agent := fiber.AcquireClient().Get("https://example.com")
+//	status, body, errs := tracing.Inject(ctx.UserContext(), agent).String()
+func Inject(ctx context.Context, agent *fiber.Agent) *fiber.Agent {
+	Propagator.Inject(ctx, HeaderCarrier{&agent.Request().Header})
+	return agent
+}
+
+// StartClientSpan membuka span client untuk request keluar lalu meng-inject header dari span tersebut
+// sehingga service tujuan menjadi child span ini. end dipanggil dengan hasil agent.String() / Bytes()
+func StartClientSpan(ctx context.Context, provider trace.TracerProvider, agent *fiber.Agent) (context.Context, func(status int, errs []error)) {
+	request := agent.Request()
+	method := string(request.Header.Method())
+	ctx, span := provider.Tracer(InstrumentationName).Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(method),
+			semconv.ServerAddress(string(request.URI().Host())),
+			// query tidak dicatat, bisa berisi signature atau token
+			semconv.URLPath(string(request.URI().Path())),
+		),
+	)
+	Inject(ctx, agent)
+
+	return ctx, func(status int, errs []error) {
+		defer span.End()
+		for _, err := range errs {
+			span.RecordError(err)
+		}
+		if status > 0 {
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		}
+		if len(errs) > 0 || status >= fiber.StatusInternalServerError {
+			span.SetStatus(codes.Error, strings.TrimSpace(http.StatusText(status)))
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"belajar-golang-fiber/config"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// InstrumentationName adalah nama tracer untuk semua span yang dibuat aplikasi
+const InstrumentationName = "belajar-golang-fiber"
+
+// Propagator membaca dan menulis header W3C traceparent, tracestate dan baggage
+var Propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewExporter memilih tujuan span sesuai cfg.Exporter: otlp (OTLP/HTTP ke collector) atau stdout.
+// Test memakai tracetest.NewInMemoryExporter langsung, exporter tersebut menyimpan semua span tanpa batas
+// sehingga tidak bisa dipilih dari konfigurasi
+func NewExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, error) {
+	switch cfg.Exporter {
+	case "otlp":
+		var options []otlptracehttp.Option
+		// endpoint kosong => env OTEL_EXPORTER_OTLP_ENDPOINT atau localhost:4318
+		if cfg.Endpoint != "" {
+			options = append(options, otlptracehttp.WithEndpoint(cfg.Endpoint))
+		}
+		if cfg.Insecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, options...)
+	case "stdout":
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Exporter)
+	}
+}
+
+// NewProvider membuat TracerProvider dengan sampling berdasarkan parent: jika request masuk membawa
+// traceparent yang di-sample maka span aplikasi ikut di-sample, selain itu memakai cfg.SampleRatio.
+// Shutdown wajib dipanggil saat aplikasi berhenti supaya span yang masih di-buffer terkirim
+func NewProvider(cfg config.TracingConfig, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	export := sdktrace.WithBatcher(exporter)
+	if _, ok := exporter.(*tracetest.InMemoryExporter); ok {
+		// tanpa batch supaya span langsung terbaca di test
+		export = sdktrace.WithSyncer(exporter)
+	}
+
+	return sdktrace.NewTracerProvider(
+		export,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(cfg.ServiceName))),
+	)
+}
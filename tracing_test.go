@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/logging"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/tracing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracerProvider(t *testing.T) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider(config.Default().Tracing, exporter)
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+	})
+	return provider, exporter
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing(t *testing.T) {
+	provider, exporter := newTracerProvider(t)
+	tx := testdb.New(t)
+	assert.Nil(t, tracing.InstrumentDB(tx, provider))
+	userRepository := repository.NewGormStore(tx).Users()
+
+	output := &bytes.Buffer{}
+	logger, err := logging.New(config.Default().Log, output, new(slog.LevelVar))
+	assert.Nil(t, err)
+
+	tracedApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	tracedApp.Use(middleware.NewTracing(provider), middleware.NewRequestLogger(logger, config.Default().Log))
+	tracedApp.Get("/api/users/:userId", func(ctx *fiber.Ctx) error {
+		_, err := userRepository.FindById(ctx.UserContext(), ctx.Params("userId"))
+		if errors.Is(err, repository.ErrNotFound) {
+			return exception.NotFound("user not found")
+		}
+		return err
+	})
+	tracedApp.Get("/api/error", func(ctx *fiber.Ctx) error {
+		return errors.New("connection refused")
+	})
+
+	// trace dilanjutkan dari header traceparent client
+	request := httptest.NewRequest("GET", "/api/users/Bagus", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	response, err := tracedApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+
+	spans := exporter.GetSpans()
+	assert.Equal(t, 2, len(spans))
+	query, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /api/users/:userId", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent.SpanID().String())
+	assert.Equal(t, "/api/users/:userId", spanAttribute(server, "http.route").AsString())
+	assert.Equal(t, "/api/users/Bagus", spanAttribute(server, "url.path").AsString())
+	assert.Equal(t, int64(404), spanAttribute(server, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, server.Status.Code)
+
+	// query GORM menjadi child span request, nilai parameter tidak ikut dicatat
+	assert.Equal(t, "query users", query.Name)
+	assert.Equal(t, server.SpanContext.SpanID(), query.Parent.SpanID())
+	assert.Equal(t, server.SpanContext.TraceID(), query.SpanContext.TraceID())
+	assert.Equal(t, "users", spanAttribute(query, "db.collection.name").AsString())
+	assert.Equal(t, "query", spanAttribute(query, "db.operation.name").AsString())
+	assert.Equal(t, "sqlite", spanAttribute(query, "db.system").AsString())
+	assert.NotContains(t, spanAttribute(query, "db.query.text").AsString(), "Bagus")
+
+	records := readLogRecords(t, output)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", records[0]["trace_id"])
+	assert.Equal(t, server.SpanContext.SpanID().String(), records[0]["span_id"])
+
+	// tanpa traceparent dibuat trace baru, status 500 menandai span error
+	exporter.Reset()
+	response, err = tracedApp.Test(httptest.NewRequest("GET", "/api/error", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 500, response.StatusCode)
+	spans = exporter.GetSpans()
+	assert.Equal(t, 1, len(spans))
+	assert.False(t, spans[0].Parent.IsValid())
+	assert.Equal(t, codes.Error, spans[0].Status.Code)
+}
+
+func TestTracingClient(t *testing.T) {
+	provider, exporter := newTracerProvider(t)
+
+	downstream := fiber.New(fiber.Config{DisableStartupMessage: true})
+	downstream.Use(middleware.NewTracing(provider))
+	downstream.Get("/hello", func(ctx *fiber.Ctx) error {
+		return ctx.SendString("Hello")
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	go downstream.Listener(listener)
+	defer downstream.Shutdown()
+
+	ctx, root := provider.Tracer("test").Start(context.Background(), "root")
+	client := fiber.AcquireClient()
+	defer fiber.ReleaseClient(client)
+	agent := client.Get("http://" + listener.Addr().String() + "/hello?token=rahasia")
+	_, end := tracing.StartClientSpan(ctx, provider, agent)
+	status, body, errs := agent.String()
+	end(status, errs)
+	root.End()
+
+	assert.Nil(t, errs)
+	assert.Equal(t, 200, status)
+	assert.Equal(t, "Hello", body)
+
+	// span server di service tujuan adalah child dari span client
+	spans := exporter.GetSpans()
+	assert.Equal(t, 3, len(spans))
+	server, clientSpan := spans[0], spans[1]
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, trace.SpanKindClient, clientSpan.SpanKind)
+	assert.Equal(t, root.SpanContext().TraceID(), server.SpanContext.TraceID())
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), server.Parent.SpanID())
+	assert.Equal(t, root.SpanContext().SpanID(), clientSpan.Parent.SpanID())
+	assert.Equal(t, int64(200), spanAttribute(clientSpan, "http.response.status_code").AsInt64())
+	assert.Equal(t, "/hello", spanAttribute(clientSpan, "url.path").AsString())
+}
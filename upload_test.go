@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"belajar-golang-fiber/config"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/storage"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func tusRequest(method string, target string, body []byte) *http.Request {
+	request := httptest.NewRequest(method, target, bytes.NewReader(body))
+	request.Header.Set("Tus-Resumable", "1.0.0")
+	return request
+}
+
+func createUpload(t *testing.T, fileApp *fiber.App, length int, name string) string {
+	request := tusRequest("POST", "/api/uploads", nil)
+	request.Header.Set("Upload-Length", strconv.Itoa(length))
+	request.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte(name)))
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+	assert.Equal(t, "1.0.0", response.Header.Get("Tus-Resumable"))
+	return response.Header.Get("Location")
+}
+
+func patchUpload(t *testing.T, fileApp *fiber.App, location string, offset int, chunk []byte) *http.Response {
+	request := tusRequest("PATCH", location, chunk)
+	request.Header.Set("Content-Type", "application/offset+octet-stream")
+	request.Header.Set("Upload-Offset", strconv.Itoa(offset))
+	response, err := fileApp.Test(request)
+	assert.Nil(t, err)
+	return response
+}
+
+func TestResumableUpload(t *testing.T) {
+	db := testdb.New(t)
+	fileApp, dir := newFileApp(t, repository.NewGormStore(db), 1024)
+
+	location := createUpload(t, fileApp, len(pngFile), "gambar.png")
+	assert.Regexp(t, "^/api/uploads/[0-9a-f-]{36}$", location)
+
+	response := patchUpload(t, fileApp, location, 0, pngFile[:10])
+	assert.Equal(t, 204, response.StatusCode)
+	assert.Equal(t, "10", response.Header.Get("Upload-Offset"))
+	expires, err := http.ParseTime(response.Header.Get("Upload-Expires"))
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), expires, time.Minute)
+
+	// koneksi terputus, client menanyakan offset terakhir
+	response, err = fileApp.Test(tusRequest("HEAD", location, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, "10", response.Header.Get("Upload-Offset"))
+	assert.Equal(t, strconv.Itoa(len(pngFile)), response.Header.Get("Upload-Length"))
+	assert.Equal(t, "no-store", response.Header.Get("Cache-Control"))
+
+	response = patchUpload(t, fileApp, location, 5, pngFile[5:])
+	assert.Equal(t, 409, response.StatusCode)
+
+	response = patchUpload(t, fileApp, location, 10, pngFile[10:])
+	assert.Equal(t, 204, response.StatusCode)
+	assert.Equal(t, strconv.Itoa(len(pngFile)), response.Header.Get("Upload-Offset"))
+	fileId := response.Header.Get("X-File-Id")
+	assert.NotEqual(t, "", fileId)
+	assert.Equal(t, 1, storedFiles(t, dir))
+
+	request := httptest.NewRequest("GET", "/api/files/"+fileId+"/content", nil)
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+	content, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, pngFile, content)
+
+	// sesi upload sudah dihapus setelah selesai
+	response, err = fileApp.Test(tusRequest("HEAD", location, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), "upload-"+filepath.Base(location)+".part"))
+	assert.Nil(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestResumableUploadRejected(t *testing.T) {
+	fileApp, dir := newFileApp(t, repository.NewMemoryStore(), 64)
+
+	response, err := fileApp.Test(tusRequest("OPTIONS", "/api/uploads", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 204, response.StatusCode)
+	assert.Equal(t, "64", response.Header.Get("Tus-Max-Size"))
+
+	request := httptest.NewRequest("POST", "/api/uploads", nil)
+	request.Header.Set("Upload-Length", "10")
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 412, response.StatusCode)
+	assert.Equal(t, "1.0.0", response.Header.Get("Tus-Version"))
+
+	request = tusRequest("POST", "/api/uploads", nil)
+	request.Header.Set("Upload-Length", "65")
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 413, response.StatusCode)
+
+	location := createUpload(t, fileApp, 4, "data.txt")
+
+	// sesi upload hanya bisa diakses pemiliknya
+	request = tusRequest("HEAD", location, nil)
+	request.Header.Set("X-User", "uploader-2")
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+
+	response = patchUpload(t, fileApp, location, 0, []byte("lebih dari empat"))
+	assert.Equal(t, 413, response.StatusCode)
+
+	request = tusRequest("PATCH", location, []byte("abcd"))
+	request.Header.Set("Upload-Offset", "0")
+	response, err = fileApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 415, response.StatusCode)
+
+	response, err = fileApp.Test(tusRequest("DELETE", location, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 204, response.StatusCode)
+	response = patchUpload(t, fileApp, location, 0, []byte("abcd"))
+	assert.Equal(t, 404, response.StatusCode)
+
+	// isi file yang jenisnya tidak diizinkan ditolak saat upload selesai
+	location = createUpload(t, fileApp, 6, "index.html")
+	response = patchUpload(t, fileApp, location, 0, []byte("<html>"))
+	assert.Equal(t, 415, response.StatusCode)
+	response, err = fileApp.Test(tusRequest("HEAD", location, nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+	assert.Equal(t, 0, storedFiles(t, dir))
+}
+
+func TestResumableUploadExpiration(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	local, err := storage.NewLocal(t.TempDir())
+	assert.Nil(t, err)
+	uploadConfig := config.UploadConfig{MaxSize: 64, MaxFiles: 1, AllowedTypes: "text/plain", TempDir: t.TempDir(), Expiration: time.Hour}
+	fileService := service.NewFileService(store, local, security.NewURLSigner("rahasia"), uploadConfig, config.Default().Storage)
+	uploadService := service.NewUploadService(store, fileService, uploadConfig)
+
+	upload, err := uploadService.Create(ctx, "uploader-1", &model.CreateUploadRequest{Name: "data.txt", Length: 4})
+	assert.Nil(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), upload.ExpiresAt, time.Minute)
+	part := filepath.Join(uploadConfig.TempDir, "upload-"+upload.ID+".part")
+	assert.FileExists(t, part)
+
+	purged, err := uploadService.Purge(ctx, time.Now().Add(-uploadConfig.Expiration))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, purged)
+
+	// sesi yang sudah lewat expiration ditolak walaupun belum dihapus job purge
+	expired := service.NewUploadService(store, fileService, config.UploadConfig{TempDir: uploadConfig.TempDir, Expiration: time.Nanosecond})
+	_, err = expired.Get(ctx, "uploader-1", upload.ID)
+	assert.True(t, exception.Is(err, exception.KindNotFound))
+
+	purged, err = uploadService.Purge(ctx, time.Now().Add(time.Minute))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, purged)
+	assert.NoFileExists(t, part)
+	_, err = uploadService.Get(ctx, "uploader-1", upload.ID)
+	assert.True(t, exception.Is(err, exception.KindNotFound))
+}
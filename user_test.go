@@ -0,0 +1,356 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"belajar-golang-fiber/controller"
+	"belajar-golang-fiber/database/testdb"
+	"belajar-golang-fiber/entity"
+	"belajar-golang-fiber/exception"
+	"belajar-golang-fiber/middleware"
+	"belajar-golang-fiber/model"
+	"belajar-golang-fiber/repository"
+	"belajar-golang-fiber/security"
+	"belajar-golang-fiber/service"
+	"belajar-golang-fiber/validation"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newUserApp(store repository.Store) *fiber.App {
+	return newUserAppAs(store, nil)
+}
+
+// newUserAppAs memakai header X-User (default admin) sebagai user yang login menggantikan session,
+// permission user diambil dari map, permissions nil berarti semua user memiliki semua permission
+func newUserAppAs(store repository.Store, permissions map[string][]string) *fiber.App {
+	userApp := fiber.New(fiber.Config{ErrorHandler: exception.ErrorHandler})
+	api := userApp.Group("/api", func(ctx *fiber.Ctx) error {
+		ctx.Locals(middleware.SessionUserKey, ctx.Get("X-User", "admin"))
+		return ctx.Next()
+	}, middleware.NewPermissions(func(ctx context.Context, userId string) ([]string, error) {
+		if permissions == nil {
+			return []string{"*"}, nil
+		}
+		return permissions[userId], nil
+	}))
+
+	userService := service.NewUserService(store, security.NewPasswordHasher(bcrypt.MinCost))
+	controller.NewUserController(userService, validation.New()).Route(api)
+	return userApp
+}
+
+func TestUserCRUD(t *testing.T) {
+	db := testdb.New(t)
+	userApp := newUserApp(repository.NewGormStore(db))
+
+	// aturan validasi nama ada di model.NameRequest
+	body := strings.NewReader(`{"id":"api-1","password":"rahasia123","name":{"last_name":"Wicaksono"}}`)
+	request := httptest.NewRequest("POST", "/api/users", body)
+	request.Header.Set("Content-Type", "application/json")
+	response, err := userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 422, response.StatusCode)
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Contains(t, string(bytes), "first_name")
+
+	body = strings.NewReader(`{"id":"api-1","password":"rahasia123","name":{"first_name":"Bagus","last_name":"Wicaksono"}}`)
+	request = httptest.NewRequest("POST", "/api/users", body)
+	request.Header.Set("Content-Type", "application/json")
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/users/api-1", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(bytes), "rahasia")
+
+	userResponse := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, userResponse))
+	assert.Equal(t, "api-1", userResponse.Data.ID)
+	assert.Equal(t, "Bagus", userResponse.Data.Name.FirstName)
+	assert.Equal(t, "Wicaksono", userResponse.Data.Name.LastName)
+
+	// id di body diabaikan, hanya middle_name yang berubah
+	body = strings.NewReader(`{"id":"api-2","password":"rahasia456","name":{"middle_name":"Testing"}}`)
+	request = httptest.NewRequest("PATCH", "/api/users/api-1", body)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", `"1"`)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	user := entity.User{}
+	assert.Nil(t, db.Take(&user, "id = ?", "api-1").Error)
+	assert.Equal(t, "Bagus", user.Name.FirstName)
+	assert.Equal(t, "Testing", user.Name.MiddleName)
+	assert.True(t, security.IsHashed(user.Password))
+
+	request = httptest.NewRequest("GET", "/api/users", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	request = httptest.NewRequest("DELETE", "/api/users/api-1", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 204, response.StatusCode)
+
+	request = httptest.NewRequest("GET", "/api/users/api-1", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+
+	// riwayat tetap ada walaupun user sudah dihapus, terbaru lebih dulu
+	request = httptest.NewRequest("GET", "/api/users/api-1/logs?size=3", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(bytes), "rahasia")
+
+	logsResponse := new(model.WebResponse[[]model.UserLogResponse])
+	assert.Nil(t, json.Unmarshal(bytes, logsResponse))
+	assert.Equal(t, int64(4), logsResponse.Paging.TotalItem)
+	assert.Equal(t, int64(2), logsResponse.Paging.TotalPage)
+
+	actions := []string{}
+	for _, log := range logsResponse.Data {
+		actions = append(actions, log.Action)
+	}
+	assert.Equal(t, []string{"delete", "password_change", "update"}, actions)
+
+	update := logsResponse.Data[2].Changes
+	assert.Equal(t, 1, len(update))
+	assert.Equal(t, "middle_name", update[0].Field)
+	assert.Equal(t, "", *update[0].Before)
+	assert.Equal(t, "Testing", *update[0].After)
+
+	assert.Equal(t, "******", *logsResponse.Data[1].Changes[0].After)
+
+	request = httptest.NewRequest("GET", "/api/users/api-1/logs?size=3&page=2", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	bytes, err = io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	logsResponse = new(model.WebResponse[[]model.UserLogResponse])
+	assert.Nil(t, json.Unmarshal(bytes, logsResponse))
+	assert.Equal(t, 1, len(logsResponse.Data))
+	assert.Equal(t, "create", logsResponse.Data[0].Action)
+}
+
+// controller dan service diuji tanpa database menggunakan repository in-memory
+func TestUserControllerMemoryStore(t *testing.T) {
+	store := repository.NewMemoryStore()
+	userApp := newUserApp(store)
+
+	body := `{"id":"memory-1","password":"rahasia123","name":{"first_name":"Bagus"}}`
+	request := httptest.NewRequest("POST", "/api/users", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, err := userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+
+	request = httptest.NewRequest("POST", "/api/users", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 409, response.StatusCode)
+
+	request = httptest.NewRequest("PATCH", "/api/users/memory-1", strings.NewReader(`{"name":{"last_name":"Wicaksono"}}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", "*")
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 200, response.StatusCode)
+
+	// password dibatasi 72 byte, bukan 72 karakter
+	request = httptest.NewRequest("PATCH", "/api/users/memory-1", strings.NewReader(`{"password":"`+strings.Repeat("é", 40)+`"}`))
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("If-Match", "*")
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 422, response.StatusCode)
+
+	user, err := store.Users().FindById(context.Background(), "memory-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "Wicaksono", user.Name.LastName)
+	assert.True(t, security.IsHashed(user.Password))
+
+	logs, total, err := store.UserLogs().FindByUserId(context.Background(), "memory-1", 1, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, "update", logs[0].Action)
+	assert.Equal(t, "create", logs[1].Action)
+
+	request = httptest.NewRequest("DELETE", "/api/users/tidak-ada", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 404, response.StatusCode)
+}
+
+func TestMemoryStoreTransactionRollback(t *testing.T) {
+	store := repository.NewMemoryStore()
+	ctx := context.Background()
+
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Users().Create(ctx, &entity.User{ID: "1"}); err != nil {
+			return err
+		}
+		// duplicate, seluruh transaksi dibatalkan
+		return tx.Users().Create(ctx, &entity.User{ID: "1"})
+	})
+	assert.ErrorIs(t, err, repository.ErrDuplicate)
+
+	_, err = store.Users().FindById(ctx, "1")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+}
+
+func TestPasswordHasher(t *testing.T) {
+	hasher := security.NewPasswordHasher(bcrypt.MinCost)
+
+	hashed, err := hasher.Hash("rahasia")
+	assert.Nil(t, err)
+	assert.True(t, security.IsHashed(hashed))
+	assert.NotEqual(t, "rahasia", hashed)
+
+	ok, needsRehash := hasher.Verify(hashed, "rahasia")
+	assert.True(t, ok)
+	assert.False(t, needsRehash)
+
+	ok, _ = hasher.Verify(hashed, "salah")
+	assert.False(t, ok)
+
+	// cost dinaikkan, hash lama perlu di-rehash
+	ok, needsRehash = security.NewPasswordHasher(bcrypt.MinCost+1).Verify(hashed, "rahasia")
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	// row lama yang masih plaintext
+	assert.False(t, security.IsHashed("rahasia"))
+	ok, needsRehash = hasher.Verify("rahasia", "rahasia")
+	assert.True(t, ok)
+	assert.True(t, needsRehash)
+
+	ok, needsRehash = hasher.Verify("rahasia", "salah")
+	assert.False(t, ok)
+	assert.False(t, needsRehash)
+
+	// batas bcrypt 72 byte menjadi error validasi, bukan internal error
+	_, err = hasher.Hash(strings.Repeat("é", 40))
+	assert.True(t, exception.Is(err, exception.KindValidation))
+}
+
+func TestUserOptimisticLocking(t *testing.T) {
+	db := testdb.New(t)
+	userApp := newUserApp(repository.NewGormStore(db))
+
+	body := strings.NewReader(`{"id":"lock-1","password":"rahasia123","name":{"first_name":"Bagus"}}`)
+	request := httptest.NewRequest("POST", "/api/users", body)
+	request.Header.Set("Content-Type", "application/json")
+	response, err := userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 201, response.StatusCode)
+	assert.Equal(t, `"1"`, response.Header.Get("ETag"))
+
+	request = httptest.NewRequest("GET", "/api/users/lock-1", nil)
+	request.Header.Set("If-None-Match", `"1"`)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, 304, response.StatusCode)
+
+	patch := func(ifMatch string, lastName string) *http.Response {
+		request := httptest.NewRequest("PATCH", "/api/users/lock-1", strings.NewReader(`{"name":{"last_name":"`+lastName+`"}}`))
+		request.Header.Set("Content-Type", "application/json")
+		request.Header.Set("If-Match", ifMatch)
+		response, err := userApp.Test(request)
+		assert.Nil(t, err)
+		return response
+	}
+
+	// tanpa If-Match ditolak dengan 428, If-Match: * sengaja melewati cek versi
+	response = patch("", "Wicaksono")
+	assert.Equal(t, 428, response.StatusCode)
+
+	// dua editor membaca versi 1, editor kedua ditolak
+	response = patch(`"1"`, "Wicaksono")
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"2"`, response.Header.Get("ETag"))
+
+	response = patch(`"1"`, "Khannedy")
+	assert.Equal(t, 412, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "application/problem+json")
+
+	response = patch(`W/"2"`, "Khannedy")
+	assert.Equal(t, 412, response.StatusCode)
+
+	response = patch(`"5", "2"`, "Khannedy")
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"3"`, response.Header.Get("ETag"))
+
+	response = patch("*", "Khannedy")
+	assert.Equal(t, 200, response.StatusCode)
+	assert.Equal(t, `"4"`, response.Header.Get("ETag"))
+
+	request = httptest.NewRequest("GET", "/api/users/lock-1", nil)
+	response, err = userApp.Test(request)
+	assert.Nil(t, err)
+	assert.Equal(t, `"4"`, response.Header.Get("ETag"))
+
+	bytes, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	userResponse := new(model.WebResponse[model.UserResponse])
+	assert.Nil(t, json.Unmarshal(bytes, userResponse))
+	assert.Equal(t, "Khannedy", userResponse.Data.Name.LastName)
+	assert.Equal(t, int64(4), userResponse.Data.Version)
+}
+
+func TestUserRepositoryConflict(t *testing.T) {
+	db := testdb.New(t)
+	stores := map[string]repository.Store{"gorm": repository.NewGormStore(db), "memory": repository.NewMemoryStore()}
+	ctx := context.Background()
+
+	for name, store := range stores {
+		users := store.Users()
+		assert.Nil(t, users.Create(ctx, &entity.User{ID: "conflict-1", Password: "rahasia", Name: entity.Name{FirstName: "Bagus"}}), name)
+
+		first, err := users.FindById(ctx, "conflict-1")
+		assert.Nil(t, err, name)
+		second, err := users.FindById(ctx, "conflict-1")
+		assert.Nil(t, err, name)
+
+		first.Name.LastName = "Wicaksono"
+		assert.Nil(t, users.Save(ctx, first), name)
+		assert.Equal(t, int64(2), first.Version, name)
+
+		second.Name.LastName = "Khannedy"
+		assert.ErrorIs(t, users.Save(ctx, second), repository.ErrConflict, name)
+		assert.Equal(t, int64(1), second.Version, name)
+
+		assert.ErrorIs(t, users.Save(ctx, &entity.User{ID: "tidak-ada", Version: 1}), repository.ErrNotFound, name)
+
+		// field yang dikosongkan ikut tersimpan
+		first.Name.LastName = ""
+		assert.Nil(t, users.Save(ctx, first), name)
+		saved, err := users.FindById(ctx, "conflict-1")
+		assert.Nil(t, err, name)
+		assert.Equal(t, "", saved.Name.LastName, name)
+		assert.Equal(t, int64(3), saved.Version, name)
+	}
+}
@@ -0,0 +1,128 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Patterns berisi regex yang bisa dipakai dengan tag `validate:"pattern=<nama>"`.
+// Regex tidak ditulis langsung di tag karena karakter seperti koma dan | punya arti khusus di validator
+var Patterns = map[string]*regexp.Regexp{
+	"username": regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`),
+}
+
+type Validator struct {
+	validate *validator.Validate
+}
+
+func New() *Validator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+
+	// nama field di response error mengikuti nama field di JSON, bukan nama field struct Go
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	validate.RegisterValidation("pattern", func(fl validator.FieldLevel) bool {
+		pattern, ok := Patterns[fl.Param()]
+		if !ok {
+			panic("validation: unknown pattern " + fl.Param())
+		}
+		return pattern.MatchString(fl.Field().String())
+	})
+
+	// maxbytes menghitung byte, bukan karakter seperti max. Dipakai untuk password karena batas bcrypt 72 byte
+	validate.RegisterValidation("maxbytes", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		if err != nil {
+			panic("validation: invalid maxbytes " + fl.Param())
+		}
+		return len(fl.Field().String()) <= limit
+	})
+
+	return &Validator{validate: validate}
+}
+
+// Struct memvalidasi struct berdasarkan tag validate, error yang dikembalikan bertipe *Error
+func (v *Validator) Struct(request any) error {
+	err := v.validate.Struct(request)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	result := &Error{}
+	for _, fieldError := range fieldErrors {
+		field := fieldError.Namespace()
+		// buang nama struct root, contoh CreateUserRequest.name.first_name => name.first_name
+		if index := strings.Index(field, "."); index >= 0 {
+			field = field[index+1:]
+		}
+
+		result.Fields = append(result.Fields, FieldError{
+			Field:   field,
+			Code:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: message(field, fieldError),
+		})
+	}
+	return result
+}
+
+type FieldError struct {
+	Field   string `json:"field" xml:"field"`
+	Code    string `json:"code" xml:"code"`
+	Param   string `json:"param,omitempty" xml:"param,omitempty"`
+	Message string `json:"message" xml:"message"`
+}
+
+// Error berisi semua field yang gagal divalidasi
+type Error struct {
+	Fields []FieldError
+}
+
+func (e *Error) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func message(field string, fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min":
+		return fmt.Sprintf("%s must be at least %s characters", field, fieldError.Param())
+	case "max":
+		return fmt.Sprintf("%s must be at most %s characters", field, fieldError.Param())
+	case "maxbytes":
+		return fmt.Sprintf("%s must be at most %s bytes", field, fieldError.Param())
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	case "pattern":
+		return fmt.Sprintf("%s has an invalid format", field)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of [%s]", field, fieldError.Param())
+	default:
+		return fmt.Sprintf("%s failed on %s validation", field, fieldError.Tag())
+	}
+}